@@ -8,6 +8,7 @@ import (
 
     "src/internal/server"
     "src/internal/blockchain"
+    "src/internal/config"
     "src/internal/database"
     "src/internal/shutdown"
 )
@@ -16,23 +17,39 @@ func main() {
     ctx, cancel := context.WithCancel(context.Background())
     defer cancel()
 
+    // Load configuration
+    cfg, err := config.Load()
+    if err != nil {
+        log.Fatalf("Failed to load configuration: %v", err)
+    }
+
     // Initialize database
-    db := database.New()
+    db := database.New(cfg.Database)
     defer func() {
         if err := db.Close(ctx); err != nil {
             log.Printf("Error closing database connection: %v", err)
         }
     }()
 
-    // Initialize server
-    server := server.NewServer(db)
-
     // Initialize blockchain listener
-    eventListener, err := blockchain.NewEventListener(db)
+    eventListener, err := blockchain.NewEventListener(db, cfg.Blockchain)
     if err != nil {
         log.Fatalf("Failed to create event listener: %v", err)
     }
 
+    // Initialize read-only node access for the HTTP services
+    caller := blockchain.NewCaller(cfg.Blockchain.NodeURL)
+    defer caller.Close()
+
+    // Load the compiled proxy implementations that deployed proxies are checked against
+    implementations, err := blockchain.ImplementationHashes(cfg.Blockchain.ArtifactsDir, "UniswapProxy", "UniswapProxyV2")
+    if err != nil {
+        log.Printf("Proxy inspection will not recognise every implementation: %v", err)
+    }
+
+    // Initialize server
+    server := server.NewServer(db, eventListener, caller, cfg.Server, cfg.Pricing, implementations)
+
     // Create error channel to catch any errors from the event listener goroutine
     listenerErrCh := make(chan error, 1)
     
@@ -51,8 +68,10 @@ func main() {
     go func() {
         select {
         case err := <-listenerErrCh:
+            // The listener only returns once its reconnect budget is exhausted;
+            // the health endpoint keeps reporting it as failed
             log.Printf("Event listener error: %v", err)
-            cancel() // Cancel context to trigger shutdown
+            cancel()
         case <-ctx.Done():
             return
         }
@@ -0,0 +1,107 @@
+// Command layoutcheck compares the storage layouts of two versions of an
+// upgradeable contract and exits with status 1 when the upgrade is unsafe:
+//
+//	go run ./cmd/layoutcheck UniswapProxy UniswapProxyV2
+//	go run ./cmd/layoutcheck -old-artifacts ../release/artifacts UniswapProxyV2 UniswapProxyV2
+//
+// Layouts are read from the Hardhat build info of the compiled contracts.
+package main
+
+import (
+    "encoding/json"
+    "flag"
+    "fmt"
+    "os"
+
+    "src/internal/config"
+    "src/internal/layout"
+)
+
+func main() {
+    defaultArtifacts := config.Default().Blockchain.ArtifactsDir
+    if dir := os.Getenv("ARTIFACTS_DIR"); dir != "" {
+        defaultArtifacts = dir
+    }
+
+    artifactsDir := flag.String("artifacts", defaultArtifacts, "Hardhat artifacts directory of the new contract")
+    oldArtifactsDir := flag.String("old-artifacts", "", "Hardhat artifacts directory of the old contract (defaults to -artifacts)")
+    asJSON := flag.Bool("json", false, "print the changes as JSON")
+    flag.Usage = func() {
+        fmt.Fprintf(flag.CommandLine.Output(), "Usage: layoutcheck [flags] <old contract> <new contract>\n")
+        flag.PrintDefaults()
+    }
+    flag.Parse()
+
+    if flag.NArg() != 2 {
+        flag.Usage()
+        os.Exit(2)
+    }
+    if *oldArtifactsDir == "" {
+        *oldArtifactsDir = *artifactsDir
+    }
+
+    oldLayout, err := layout.Load(*oldArtifactsDir, flag.Arg(0))
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Failed to load old layout: %v\n", err)
+        os.Exit(2)
+    }
+    newLayout, err := layout.Load(*artifactsDir, flag.Arg(1))
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Failed to load new layout: %v\n", err)
+        os.Exit(2)
+    }
+
+    changes, err := layout.Compare(oldLayout, newLayout)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Failed to compare layouts: %v\n", err)
+        os.Exit(2)
+    }
+
+    unsafe := 0
+    for _, change := range changes {
+        if change.Unsafe() {
+            unsafe++
+        }
+    }
+
+    if *asJSON {
+        type jsonChange struct {
+            layout.Change
+            Unsafe bool `json:"unsafe"`
+        }
+        report := struct {
+            Old     string       `json:"old"`
+            New     string       `json:"new"`
+            Safe    bool         `json:"safe"`
+            Changes []jsonChange `json:"changes"`
+        }{
+            Old:     flag.Arg(0),
+            New:     flag.Arg(1),
+            Safe:    unsafe == 0,
+            Changes: make([]jsonChange, 0, len(changes)),
+        }
+        for _, change := range changes {
+            report.Changes = append(report.Changes, jsonChange{Change: change, Unsafe: change.Unsafe()})
+        }
+        encoder := json.NewEncoder(os.Stdout)
+        encoder.SetIndent("", "  ")
+        encoder.Encode(report)
+    } else {
+        for _, change := range changes {
+            marker := " "
+            if change.Unsafe() {
+                marker = "!"
+            }
+            fmt.Printf("%s %s\n", marker, change)
+        }
+        if unsafe > 0 {
+            fmt.Printf("%s -> %s: %d unsafe storage change(s)\n", flag.Arg(0), flag.Arg(1), unsafe)
+        } else {
+            fmt.Printf("%s -> %s: storage layout is upgrade safe\n", flag.Arg(0), flag.Arg(1))
+        }
+    }
+
+    if unsafe > 0 {
+        os.Exit(1)
+    }
+}
@@ -0,0 +1,74 @@
+package blockchain
+
+import (
+    "encoding/json"
+    "fmt"
+    "os"
+    "path/filepath"
+
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/common/hexutil"
+    "github.com/ethereum/go-ethereum/crypto"
+)
+
+// Artifact is the part of a Hardhat compilation artifact
+// (artifacts/contracts/<Name>.sol/<Name>.json) the backend uses
+type Artifact struct {
+    ContractName     string `json:"contractName"`
+    SourceName       string `json:"sourceName"`
+    DeployedBytecode string `json:"deployedBytecode"`
+}
+
+// CodeHash is the keccak256 hash of the runtime bytecode, comparable with the
+// hash of the code deployed on-chain as long as the contract has no immutables
+func (a *Artifact) CodeHash() (common.Hash, error) {
+    code, err := hexutil.Decode(a.DeployedBytecode)
+    if err != nil {
+        return common.Hash{}, fmt.Errorf("invalid deployed bytecode in %s: %v", a.ContractName, err)
+    }
+    return crypto.Keccak256Hash(code), nil
+}
+
+// LoadArtifact reads the artifact of a contract compiled from contracts/<name>.sol
+func LoadArtifact(artifactsDir, name string) (*Artifact, error) {
+    path := filepath.Join(artifactsDir, "contracts", name+".sol", name+".json")
+
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read artifact %s: %v", path, err)
+    }
+
+    var artifact Artifact
+    if err := json.Unmarshal(data, &artifact); err != nil {
+        return nil, fmt.Errorf("failed to parse artifact %s: %v", path, err)
+    }
+
+    return &artifact, nil
+}
+
+// ImplementationHashes maps the runtime code hash of each named artifact to
+// its contract name. Artifacts that are missing, e.g. because the contracts
+// have not been compiled, are skipped and reported in the returned error.
+func ImplementationHashes(artifactsDir string, names ...string) (map[string]string, error) {
+    hashes := make(map[string]string, len(names))
+    var failed []error
+
+    for _, name := range names {
+        artifact, err := LoadArtifact(artifactsDir, name)
+        if err != nil {
+            failed = append(failed, err)
+            continue
+        }
+        hash, err := artifact.CodeHash()
+        if err != nil {
+            failed = append(failed, err)
+            continue
+        }
+        hashes[hash.Hex()] = artifact.ContractName
+    }
+
+    if len(failed) > 0 {
+        return hashes, fmt.Errorf("failed to load %d of %d artifacts: %v", len(failed), len(names), failed)
+    }
+    return hashes, nil
+}
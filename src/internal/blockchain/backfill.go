@@ -0,0 +1,104 @@
+package blockchain
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "math/big"
+
+    "github.com/ethereum/go-ethereum"
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/core/types"
+)
+
+// backfill replays the events emitted between each contract's checkpoint (or
+// deployment block) and the current head. It must run after the live
+// subscription has been created so that no block falls between the two.
+func (el *EventListener) backfill(ctx context.Context) error {
+    head, err := el.client.BlockNumber(ctx)
+    if err != nil {
+        return fmt.Errorf("failed to get latest block number: %v", err)
+    }
+    el.head = max(el.head, head)
+
+    for _, contract := range el.contracts {
+        address := common.HexToAddress(contract.Address)
+
+        from := contract.DeploymentBlock
+        checkpoint, err := el.db.GetCheckpoint(ctx, address.Hex())
+        if err != nil {
+            return err
+        }
+        if checkpoint != nil {
+            from = checkpoint.LastBlock + 1
+            el.cursors[address] = checkpoint.LastBlock
+        }
+
+        if from <= head {
+            log.Printf("Backfilling events for %s from block %d to %d", address.Hex(), from, head)
+        }
+
+        for start := from; start <= head; start += el.blockRange {
+            end := min(start+el.blockRange-1, head)
+
+            logs, err := el.client.FilterLogs(ctx, ethereum.FilterQuery{
+                FromBlock: new(big.Int).SetUint64(start),
+                ToBlock:   new(big.Int).SetUint64(end),
+                Addresses: []common.Address{address},
+                Topics:    [][]common.Hash{el.topics},
+            })
+            if err != nil {
+                return fmt.Errorf("failed to filter logs for %s in blocks %d-%d: %v", address.Hex(), start, end, err)
+            }
+
+            // The checkpoint stays before a log that fails, so it is retried
+            for _, vLog := range logs {
+                if err := el.processEvent(vLog); err != nil {
+                    return fmt.Errorf("failed to process log %d of tx %s: %v", vLog.Index, vLog.TxHash.Hex(), err)
+                }
+            }
+
+            if err := el.saveCheckpoint(ctx, address, end); err != nil {
+                return err
+            }
+        }
+    }
+
+    return nil
+}
+
+// handleLog processes a log received from the live subscription, skipping logs
+// already covered by the backfill and advancing the contract's checkpoint. A
+// log that cannot be processed yet is returned as an error, so the listener
+// reconnects and the backfill retries it from the checkpoint.
+func (el *EventListener) handleLog(ctx context.Context, vLog types.Log) error {
+    if vLog.Removed {
+        el.handleRemovedLog(ctx, vLog)
+        return nil
+    }
+
+    cursor, ok := el.cursors[vLog.Address]
+    if ok && vLog.BlockNumber <= cursor {
+        return nil // Already processed during backfill
+    }
+
+    if err := el.processEvent(vLog); err != nil {
+        return fmt.Errorf("failed to process log %d of tx %s: %v", vLog.Index, vLog.TxHash.Hex(), err)
+    }
+
+    // Logs arrive in block order, so every block before this one is complete
+    if vLog.BlockNumber > 0 && (!ok || vLog.BlockNumber-1 > cursor) {
+        if err := el.saveCheckpoint(ctx, vLog.Address, vLog.BlockNumber-1); err != nil {
+            log.Printf("Failed to save checkpoint: %v", err)
+        }
+    }
+    return nil
+}
+
+func (el *EventListener) saveCheckpoint(ctx context.Context, address common.Address, block uint64) error {
+    if err := el.db.SaveCheckpoint(ctx, address.Hex(), block); err != nil {
+        return err
+    }
+    el.cursors[address] = block
+    return nil
+}
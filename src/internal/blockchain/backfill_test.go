@@ -0,0 +1,71 @@
+package blockchain
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/core/types"
+)
+
+// headerlessClient fails every header request, like a node that has not
+// served the block yet
+type headerlessClient struct {
+    simClient
+}
+
+func (headerlessClient) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+    return nil, errors.New("header not found")
+}
+
+// Logs whose block timestamp cannot be read are not stored with a made-up
+// time; the backfill fails before their checkpoint and retries them
+func TestBackfillRetriesLogsWithoutTimestamp(t *testing.T) {
+    chain := newTestChain(t)
+    db := newTestStore(t)
+    el := newTestListener(t, chain, db)
+    ctx := context.Background()
+
+    client, _ := el.dial(ctx, "")
+    el.client = headerlessClient{client.(simClient)}
+    el.topics = append([]common.Hash{transferTopic}, PoolEventTopics...)
+    if _, err := el.loadContracts(ctx); err != nil {
+        t.Fatal(err)
+    }
+
+    block1, block2 := buildBlocks(chain)
+    if err := el.backfill(ctx); err == nil {
+        t.Fatal("backfill() succeeded without block timestamps")
+    }
+    if !indexed(db) {
+        t.Fatal("events were stored without their block timestamp")
+    }
+    for _, address := range []common.Address{testToken, testPool} {
+        if checkpoint, err := db.GetCheckpoint(ctx, address.Hex()); err != nil || checkpoint != nil {
+            t.Errorf("checkpoint of %s = %+v, %v, want none", address.Hex(), checkpoint, err)
+        }
+    }
+
+    el.client = client
+    if err := el.backfill(ctx); err != nil {
+        t.Fatal(err)
+    }
+    if !indexed(db, block1, block2) {
+        t.Fatal("the retried backfill did not index blocks 1 and 2")
+    }
+    transfers, err := db.GetTransactionsByToken(ctx, testToken.Hex())
+    if err != nil {
+        t.Fatal(err)
+    }
+    for _, tx := range transfers {
+        header, err := client.HeaderByHash(ctx, common.HexToHash(tx.BlockHash))
+        if err != nil {
+            t.Fatal(err)
+        }
+        if want := time.Unix(int64(header.Time), 0).UTC(); !tx.Timestamp.Equal(want) {
+            t.Errorf("timestamp of %s/%d = %s, want the block time %s", tx.TxHash, tx.LogIndex, tx.Timestamp, want)
+        }
+    }
+}
@@ -0,0 +1,107 @@
+package blockchain
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "math/big"
+    "sync"
+
+    "github.com/ethereum/go-ethereum"
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/ethclient"
+    "github.com/ethereum/go-ethereum/rpc"
+)
+
+// Caller performs read-only calls against the node for the HTTP services.
+// It dials lazily and redials after a failed connection attempt or a call
+// that lost the connection.
+type Caller struct {
+    nodeURL string
+    mu      sync.Mutex
+    client  *ethclient.Client
+}
+
+func NewCaller(nodeURL string) *Caller {
+    return &Caller{
+        nodeURL: nodeURL,
+    }
+}
+
+func (c *Caller) dial(ctx context.Context) (*callerClient, error) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    if c.client == nil {
+        client, err := ethclient.DialContext(ctx, c.nodeURL)
+        if err != nil {
+            return nil, fmt.Errorf("failed to connect to the Ethereum client: %v", err)
+        }
+        c.client = client
+    }
+    return &callerClient{Client: c.client, caller: c}, nil
+}
+
+// drop closes client after a connection error so the next call redials,
+// unless another call already replaced it
+func (c *Caller) drop(client *ethclient.Client) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    if c.client == client {
+        c.client.Close()
+        c.client = nil
+    }
+}
+
+// Close releases the node connection
+func (c *Caller) Close() {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    if c.client != nil {
+        c.client.Close()
+        c.client = nil
+    }
+}
+
+// callerClient is the connection handed out by Caller.dial. It drops the
+// connection from the Caller when a call fails in transport.
+type callerClient struct {
+    *ethclient.Client
+    caller *Caller
+}
+
+func (cc *callerClient) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+    result, err := cc.Client.CallContract(ctx, msg, blockNumber)
+    cc.check(err)
+    return result, err
+}
+
+func (cc *callerClient) StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
+    result, err := cc.Client.StorageAt(ctx, account, key, blockNumber)
+    cc.check(err)
+    return result, err
+}
+
+func (cc *callerClient) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+    result, err := cc.Client.CodeAt(ctx, account, blockNumber)
+    cc.check(err)
+    return result, err
+}
+
+func (cc *callerClient) check(err error) {
+    if isConnectionError(err) {
+        cc.caller.drop(cc.Client)
+    }
+}
+
+// isConnectionError reports whether err came from the transport rather than
+// from the node answering the request (e.g. a reverted call)
+func isConnectionError(err error) bool {
+    if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+        return false
+    }
+    var rpcErr rpc.Error
+    return !errors.As(err, &rpcErr)
+}
@@ -4,28 +4,35 @@ import (
     "encoding/json"
     "os"
     "path/filepath"
+    "strings"
 		"fmt"
+
+    "github.com/ethereum/go-ethereum/accounts/abi"
+    "github.com/ethereum/go-ethereum/common"
+
+    "src/internal/database"
 )
 
 type TokenConfig struct {
     Address string          `json:"address"`
     ABI     json.RawMessage `json:"abi"`
+    Receipt *struct {
+        BlockNumber uint64 `json:"blockNumber"`
+    } `json:"receipt,omitempty"`
 }
 
-func LoadTokenConfig(filename string) (*TokenConfig, error) {
-    // Get the current working directory
-    cwd, err := os.Getwd()
-    if err != nil {
-        return nil, err
-    }
-
-    // Navigate up one level if we're in the src directory
-    if filepath.Base(cwd) == "src" {
-        cwd = filepath.Dir(cwd)
+// DeploymentBlock returns the block the contract was deployed in, or 0 when
+// the deployment file does not include a receipt
+func (c *TokenConfig) DeploymentBlock() uint64 {
+    if c.Receipt == nil {
+        return 0
     }
+    return c.Receipt.BlockNumber
+}
 
-    // Construct the path to the deployments directory
-    path := filepath.Join(cwd, "deployments", "hardhat", filename)
+func LoadTokenConfig(deploymentsDir, filename string) (*TokenConfig, error) {
+    // Construct the path to the deployment file
+    path := filepath.Join(deploymentsDir, filename)
     
     data, err := os.ReadFile(path)
     if err != nil {
@@ -38,4 +45,71 @@ func LoadTokenConfig(filename string) (*TokenConfig, error) {
     }
 
     return &config, nil
-}
\ No newline at end of file
+}
+
+// LoadDeployments reads every deployment file (e.g. Token1.json, UniswapV3Pool.json)
+// in the deployments directory and classifies it by its ABI. Files whose ABI does
+// not match a supported kind are skipped.
+func LoadDeployments(deploymentsDir string) ([]*database.Contract, error) {
+    matches, err := filepath.Glob(filepath.Join(deploymentsDir, "*.json"))
+    if err != nil {
+        return nil, fmt.Errorf("failed to list deployments: %v", err)
+    }
+
+    contracts := make([]*database.Contract, 0, len(matches))
+    for _, match := range matches {
+        filename := filepath.Base(match)
+        config, err := LoadTokenConfig(deploymentsDir, filename)
+        if err != nil {
+            return nil, err
+        }
+
+        kind, err := contractKind(config.ABI)
+        if err != nil {
+            return nil, fmt.Errorf("failed to parse ABI in %s: %v", filename, err)
+        }
+        if kind == "" {
+            continue
+        }
+
+        contracts = append(contracts, &database.Contract{
+            Address:         common.HexToAddress(config.Address).Hex(),
+            Kind:            kind,
+            Name:            strings.TrimSuffix(filename, ".json"),
+            ABI:             string(config.ABI),
+            DeploymentBlock: config.DeploymentBlock(),
+        })
+    }
+
+    return contracts, nil
+}
+
+// contractKind infers the registry kind of a contract from its ABI
+func contractKind(definition json.RawMessage) (string, error) {
+    parsed, err := abi.JSON(strings.NewReader(string(definition)))
+    if err != nil {
+        return "", err
+    }
+
+    switch {
+    case hasMethod(parsed, "createPool"):
+        return database.ContractKindFactory, nil
+    case hasMethod(parsed, "slot0"):
+        return database.ContractKindV3Pool, nil
+    case hasEvent(parsed, "Upgraded") || hasMethod(parsed, "upgradeToAndCall"):
+        return database.ContractKindProxy, nil
+    case hasEvent(parsed, "Transfer") && hasMethod(parsed, "balanceOf"):
+        return database.ContractKindERC20, nil
+    }
+    return "", nil
+}
+
+func hasMethod(parsed abi.ABI, name string) bool {
+    _, ok := parsed.Methods[name]
+    return ok
+}
+
+func hasEvent(parsed abi.ABI, name string) bool {
+    _, ok := parsed.Events[name]
+    return ok
+}
@@ -10,10 +10,13 @@ type Event struct {
 	TransactionHash common.Hash
 	BlockNumber     uint64
 	BlockHash       common.Hash
-	EventType       string    // "Mint" or "Burn"
+	LogIndex        uint
+	EventType       string    // "Mint", "Burn" or "Transfer"
 	TokenAddress    string    // Which token contract
-	Account         string    // Address involved in the event
-	Amount          *big.Int  // Amount minted or burned
+	Account         string    // Recipient of a mint, holder of a burn, sender of a transfer
+	From            string
+	To              string
+	Amount          *big.Int  // Amount transferred
 }
 
 func ParseEvent(log types.Log, contractABI string) (*Event, error) {
@@ -22,22 +25,38 @@ func ParseEvent(log types.Log, contractABI string) (*Event, error) {
 		TransactionHash: log.TxHash,
 		BlockNumber:     log.BlockNumber,
 		BlockHash:       log.BlockHash,
+		LogIndex:        log.Index,
 		TokenAddress:    log.Address.Hex(),
 	}
 
+	if len(log.Topics) == 0 {
+		return event, nil
+	}
+
 	// Parse based on event signature
 	switch log.Topics[0].Hex() {
 	case "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef": // Transfer event
+		if len(log.Topics) != 3 {
+			return event, nil // Not an ERC-20 Transfer (e.g. ERC-721 indexes the token ID)
+		}
+
+		event.From = common.HexToAddress(log.Topics[1].Hex()).Hex()
+		event.To = common.HexToAddress(log.Topics[2].Hex()).Hex()
+
 		if log.Topics[1] == common.HexToHash("0x0000000000000000000000000000000000000000000000000000000000000000") {
 			// Mint event (from address is 0x0)
 			event.EventType = "Mint"
-			event.Account = common.HexToAddress(log.Topics[2].Hex()).Hex()
+			event.Account = event.To
 		} else if log.Topics[2] == common.HexToHash("0x0000000000000000000000000000000000000000000000000000000000000000") {
 			// Burn event (to address is 0x0)
 			event.EventType = "Burn"
-			event.Account = common.HexToAddress(log.Topics[1].Hex()).Hex()
+			event.Account = event.From
+		} else {
+			event.EventType = "Transfer"
+			event.Account = event.From
 		}
-		
+
+
 		// Parse amount from data
 		amount := new(big.Int)
 		amount.SetBytes(log.Data)
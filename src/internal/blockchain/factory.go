@@ -0,0 +1,126 @@
+package blockchain
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "math/big"
+
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/core/types"
+
+    "src/internal/database"
+)
+
+// PoolCreatedTopic is the signature of UniswapV3Factory's PoolCreated event
+var PoolCreatedTopic = common.HexToHash("0x783cca1c0412dd0d695e784568c96da2e9c22ff989357a2e8b1d9b2b4e6b7118")
+
+const uniswapV3FactoryABI = `[
+    {"type":"event","name":"PoolCreated","anonymous":false,"inputs":[
+        {"name":"token0","type":"address","indexed":true},
+        {"name":"token1","type":"address","indexed":true},
+        {"name":"fee","type":"uint24","indexed":true},
+        {"name":"tickSpacing","type":"int24","indexed":false},
+        {"name":"pool","type":"address","indexed":false}]}
+]`
+
+var factoryABI = mustParseABI(uniswapV3FactoryABI)
+
+type PoolCreatedEvent struct {
+    TransactionHash common.Hash
+    BlockNumber     uint64
+    FactoryAddress  string
+    PoolAddress     string
+    Token0Address   string
+    Token1Address   string
+    Fee             uint32
+    TickSpacing     int
+}
+
+// ParsePoolCreatedEvent decodes a PoolCreated log, returning nil for any other event
+func ParsePoolCreatedEvent(log types.Log) (*PoolCreatedEvent, error) {
+    if len(log.Topics) == 0 || log.Topics[0] != PoolCreatedTopic {
+        return nil, nil
+    }
+    if len(log.Topics) != 4 {
+        return nil, fmt.Errorf("invalid PoolCreated log: expected 4 topics, got %d", len(log.Topics))
+    }
+
+    data := make(map[string]interface{})
+    if err := factoryABI.UnpackIntoMap(data, "PoolCreated", log.Data); err != nil {
+        return nil, fmt.Errorf("failed to decode PoolCreated data: %v", err)
+    }
+
+    return &PoolCreatedEvent{
+        TransactionHash: log.TxHash,
+        BlockNumber:     log.BlockNumber,
+        FactoryAddress:  log.Address.Hex(),
+        PoolAddress:     data["pool"].(common.Address).Hex(),
+        Token0Address:   common.HexToAddress(log.Topics[1].Hex()).Hex(),
+        Token1Address:   common.HexToAddress(log.Topics[2].Hex()).Hex(),
+        Fee:             uint32(new(big.Int).SetBytes(log.Topics[3].Bytes()).Uint64()),
+        TickSpacing:     int(data["tickSpacing"].(*big.Int).Int64()),
+    }, nil
+}
+
+// processFactoryEvent records a newly created pool and registers it so the
+// listener starts indexing it straight away
+func (el *EventListener) processFactoryEvent(vLog types.Log) error {
+    event, err := ParsePoolCreatedEvent(vLog)
+    if err != nil {
+        log.Printf("Failed to parse factory event: %v", err)
+        return nil
+    }
+    if event == nil {
+        return nil
+    }
+
+    ctx := context.Background()
+    createdAt, err := el.blockTime(ctx, vLog.BlockHash)
+    if err != nil {
+        return err
+    }
+    pool := &database.Pool{
+        Address:        event.PoolAddress,
+        FactoryAddress: event.FactoryAddress,
+        Token0Address:  event.Token0Address,
+        Token1Address:  event.Token1Address,
+        Fee:            event.Fee,
+        TickSpacing:    event.TickSpacing,
+        ChainID:        el.chainID,
+        TxHash:         event.TransactionHash.Hex(),
+        BlockNumber:    event.BlockNumber,
+        BlockHash:      vLog.BlockHash.Hex(),
+        CreatedAt:      createdAt,
+    }
+    if err := el.db.SavePool(ctx, pool); err != nil {
+        log.Printf("Failed to save pool: %v", err)
+        return nil
+    }
+
+    contract := &database.Contract{
+        Address:         event.PoolAddress,
+        Kind:            database.ContractKindV3Pool,
+        Token0Address:   event.Token0Address,
+        Token1Address:   event.Token1Address,
+        DeploymentBlock: event.BlockNumber,
+        BlockHash:       vLog.BlockHash.Hex(),
+    }
+    if err := el.db.SeedContract(ctx, contract); err != nil {
+        log.Printf("Failed to register pool: %v", err)
+        return nil
+    }
+
+    if _, watched := el.watched[common.HexToAddress(event.PoolAddress)]; !watched {
+        el.Reload()
+    }
+
+    log.Printf("Discovered pool %s (%s/%s, fee %d, tick spacing %d) at block %d",
+        event.PoolAddress,
+        event.Token0Address,
+        event.Token1Address,
+        event.Fee,
+        event.TickSpacing,
+        event.BlockNumber)
+    return nil
+}
@@ -0,0 +1,45 @@
+package blockchain
+
+import (
+    "context"
+    "log"
+    "math/big"
+
+    "github.com/ethereum/go-ethereum/rpc"
+
+    "src/internal/database"
+)
+
+// status returns the finality status an event in the given block is stored with
+func (el *EventListener) status(blockNumber uint64) string {
+    if el.head >= blockNumber+el.confirmations {
+        return database.StatusConfirmed
+    }
+    return database.StatusPending
+}
+
+// confirm promotes pending events that are at least confirmations blocks deep,
+// or already finalized according to the node
+func (el *EventListener) confirm(ctx context.Context) {
+    if el.confirmations == 0 || el.head < el.confirmations {
+        return
+    }
+    upTo := el.head - el.confirmations
+
+    // Nodes without finality support (e.g. older Hardhat versions) return an error
+    // here, and dev nodes such as Hardhat and anvil report the latest block as
+    // finalized, so the tag only counts when it trails the head
+    if finalized, err := el.client.HeaderByNumber(ctx, big.NewInt(int64(rpc.FinalizedBlockNumber))); err == nil && finalized.Number.Uint64() < el.head {
+        upTo = max(upTo, finalized.Number.Uint64())
+    }
+
+    confirmed, err := el.db.ConfirmEvents(ctx, upTo)
+    if err != nil {
+        log.Printf("Failed to confirm events: %v", err)
+        return
+    }
+
+    if confirmed > 0 {
+        log.Printf("Confirmed %d events up to block %d", confirmed, upTo)
+    }
+}
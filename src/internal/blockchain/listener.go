@@ -4,6 +4,7 @@ import (
     "context"
     "fmt"
     "log"
+    "math/big"
     "time"
 
     "github.com/ethereum/go-ethereum"
@@ -11,126 +12,377 @@ import (
     "github.com/ethereum/go-ethereum/core/types"
     "github.com/ethereum/go-ethereum/ethclient"
     
+    "src/internal/config"
     "src/internal/database"
 )
 
+// chainClient is the part of the node API the listener uses. It is implemented
+// by *ethclient.Client and, in tests, by the simulated backend's client.
+type chainClient interface {
+    ethereum.BlockNumberReader
+    ethereum.ChainIDReader
+    ethereum.ChainReader
+    ethereum.LogFilterer
+    ethereum.TransactionReader
+    ethereum.ContractCaller
+    Close()
+}
+
+// dialNode connects to the node at url over its JSON-RPC endpoint
+func dialNode(ctx context.Context, url string) (chainClient, error) {
+    return ethclient.DialContext(ctx, url)
+}
+
 type EventListener struct {
-    client          *ethclient.Client
-    token1Config    *TokenConfig
-    token2Config    *TokenConfig
+    client          chainClient
+    dial            func(ctx context.Context, url string) (chainClient, error)
+    nodeURL         string
+    contracts       []*database.Contract
+    watched         map[common.Address]*database.Contract
+    topics          []common.Hash
+    cursors         map[common.Address]uint64
+    blocks          *blockTracker
+    blockRange      uint64
+    confirmations   uint64
+    maxReconnects   int
+    chainID         uint64
+    blockTimes      map[common.Hash]time.Time
+    conn            connectionState
+    reload          chan struct{}
+    head            uint64
     db             database.Service
 }
 
-func NewEventListener(db database.Service) (*EventListener, error) {
-    // Load token configurations
-    token1Config, err := LoadTokenConfig("Token1.json")
+func NewEventListener(db database.Service, cfg config.BlockchainConfig) (*EventListener, error) {
+    // Seed the contract registry from the deployments directory
+    deployments, err := LoadDeployments(cfg.DeploymentsDir)
     if err != nil {
-        return nil, fmt.Errorf("failed to load Token1 config: %v", err)
+        return nil, fmt.Errorf("failed to load deployments: %v", err)
     }
 
-    token2Config, err := LoadTokenConfig("Token2.json")
-    if err != nil {
-        return nil, fmt.Errorf("failed to load Token2 config: %v", err)
+    // Watch the configured factory for new pools
+    if cfg.FactoryAddress != "" {
+        deployments = append(deployments, &database.Contract{
+            Address: common.HexToAddress(cfg.FactoryAddress).Hex(),
+            Kind:    database.ContractKindFactory,
+            Name:    "UniswapV3Factory",
+        })
+    }
+
+    ctx := context.Background()
+    for _, contract := range deployments {
+        if err := db.SeedContract(ctx, contract); err != nil {
+            return nil, err
+        }
     }
 
     return &EventListener{
-        token1Config: token1Config,
-        token2Config: token2Config,
+        watched:      make(map[common.Address]*database.Contract),
+        cursors:      make(map[common.Address]uint64),
+        blocks:       newBlockTracker(),
+        blockTimes:   make(map[common.Hash]time.Time),
+        reload:       make(chan struct{}, 1),
+        dial:         dialNode,
+        nodeURL:      cfg.NodeURL,
+        blockRange:   cfg.BackfillBlockRange,
+        confirmations: cfg.ConfirmationDepth,
+        maxReconnects: cfg.ReconnectMaxAttempts,
         db:          db,
     }, nil
 }
 
-// Start initializes the websocket connection and starts listening for events
-func (el *EventListener) Start(ctx context.Context) error {
-    // Connect to local hardhat node via WebSocket
-    client, err := ethclient.Dial("ws://localhost:8545")
+// Reload makes the listener re-read the contract registry and resubscribe
+func (el *EventListener) Reload() {
+    select {
+    case el.reload <- struct{}{}:
+    default: // A reload is already pending
+    }
+}
+
+// listen initializes the websocket connection and listens for events until
+// the connection fails, the registry changes or ctx is cancelled
+func (el *EventListener) listen(ctx context.Context) error {
+    // Connect to the node via WebSocket
+    client, err := el.dial(ctx, el.nodeURL)
     if err != nil {
         return fmt.Errorf("failed to connect to the Ethereum client: %v", err)
     }
+    defer client.Close()
     el.client = client
 
-    // Create filter query for both tokens
+    chainID, err := client.ChainID(ctx)
+    if err != nil {
+        return fmt.Errorf("failed to get chain ID: %v", err)
+    }
+    el.chainID = chainID.Uint64()
+
+    addresses, err := el.loadContracts(ctx)
+    if err != nil {
+        return err
+    }
+
+    // An empty address list would match every contract on the chain
+    if len(addresses) == 0 {
+        el.connected()
+        log.Println("No contracts registered, waiting for registry changes")
+        select {
+        case <-el.reload:
+            return errReload
+        case <-ctx.Done():
+            return nil
+        }
+    }
+
+    // Create filter query for all watched contracts
+    topics := []common.Hash{
+        common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"), // Transfer event signature
+    }
+    topics = append(topics, PoolEventTopics...)
+    topics = append(topics, PoolCreatedTopic)
+    topics = append(topics, ProxyEventTopics...)
+    topics = append(topics, TokenAdminTopics...)
+    el.topics = topics
+
     query := ethereum.FilterQuery{
-        Addresses: []common.Address{
-            common.HexToAddress(el.token1Config.Address),
-            common.HexToAddress(el.token2Config.Address),
-        },
-        Topics: [][]common.Hash{
-            {
-                common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"), // Transfer event signature
-            },
-        },
+        Addresses: addresses,
+        Topics:    [][]common.Hash{topics},
     }
 
     // Create channel for logs
     logs := make(chan types.Log)
 
-    // Subscribe to the events
+    // Subscribe to the events before catching up so no block is missed in between
     sub, err := el.client.SubscribeFilterLogs(ctx, query, logs)
     if err != nil {
         return fmt.Errorf("failed to subscribe to contract events: %v", err)
     }
+    defer sub.Unsubscribe()
+
+    // Follow new heads to detect chain reorganizations
+    headers := make(chan *types.Header)
+    headSub, err := el.client.SubscribeNewHead(ctx, headers)
+    if err != nil {
+        return fmt.Errorf("failed to subscribe to new heads: %v", err)
+    }
+    defer headSub.Unsubscribe()
+
+    // Catch up on events emitted since the last checkpoint
+    if err := el.backfill(ctx); err != nil {
+        return fmt.Errorf("backfill failed: %v", err)
+    }
+    el.connected()
 
-    log.Printf("Started listening for events on tokens: %s, %s", 
-        el.token1Config.Address, 
-        el.token2Config.Address)
+    for _, contract := range el.contracts {
+        if contract.Kind == database.ContractKindV3Pool {
+            log.Printf("Started listening for events on pool %s (%s/%s)",
+                contract.Address,
+                contract.Token0Address,
+                contract.Token1Address)
+        } else {
+            log.Printf("Started listening for events on %s %s", contract.Kind, contract.Address)
+        }
+    }
 
     // Start listening for events
     for {
         select {
         case err := <-sub.Err():
             return fmt.Errorf("subscription error: %v", err)
+        case err := <-headSub.Err():
+            return fmt.Errorf("new head subscription error: %v", err)
+        case header := <-headers:
+            if err := el.handleHeader(ctx, header); err != nil {
+                log.Printf("Failed to handle new head: %v", err)
+            }
         case vLog := <-logs:
-            el.processEvent(vLog)
+            // Blocks rewound by a reorg are replayed by the backfill after the reload
+            if len(el.reload) > 0 {
+                return errReload
+            }
+            if err := el.handleLog(ctx, vLog); err != nil {
+                return err
+            }
+        case <-el.reload:
+            return errReload
         case <-ctx.Done():
             return nil
         }
     }
 }
 
-func (el *EventListener) processEvent(log types.Log) {
-    var contractABI string
-    switch log.Address.Hex() {
-    case el.token1Config.Address:
-        contractABI = string(el.token1Config.ABI)
-    case el.token2Config.Address:
-        contractABI = string(el.token2Config.ABI)
+// loadContracts reads the active contracts from the registry, resolving the
+// token pair of pools registered without one, and returns the addresses to watch
+func (el *EventListener) loadContracts(ctx context.Context) ([]common.Address, error) {
+    contracts, err := el.db.GetContracts(ctx, true)
+    if err != nil {
+        return nil, err
+    }
+
+    el.contracts = nil
+    el.watched = make(map[common.Address]*database.Contract)
+    el.cursors = make(map[common.Address]uint64)
+
+    var addresses []common.Address
+    for _, contract := range contracts {
+        switch contract.Kind {
+        case database.ContractKindERC20, database.ContractKindFactory, database.ContractKindProxy:
+        case database.ContractKindV3Pool:
+            if contract.Token0Address == "" || contract.Token1Address == "" {
+                if err := ResolvePoolTokens(ctx, el.client, contract); err != nil {
+                    return nil, err
+                }
+                if err := el.db.SaveContract(ctx, contract); err != nil {
+                    return nil, err
+                }
+            }
+        default:
+            continue // No events indexed for this kind
+        }
+
+        address := common.HexToAddress(contract.Address)
+        el.contracts = append(el.contracts, contract)
+        el.watched[address] = contract
+        addresses = append(addresses, address)
+    }
+
+    return addresses, nil
+}
+
+// processEvent saves the event a log describes. Logs that cannot be decoded
+// are skipped; an error means the log could not be processed yet, e.g. because
+// its block timestamp was unavailable, and must be retried.
+func (el *EventListener) processEvent(vLog types.Log) error {
+    contract, ok := el.watched[vLog.Address]
+    if !ok {
+        return nil
+    }
+
+    switch contract.Kind {
+    case database.ContractKindV3Pool:
+        return el.processPoolEvent(vLog, contract)
+    case database.ContractKindFactory:
+        return el.processFactoryEvent(vLog)
+    case database.ContractKindProxy:
+        return el.processProxyEvent(vLog)
+    }
+
+    if handled, err := el.processTokenAdminEvent(vLog); handled || err != nil {
+        return err
     }
 
-    event, err := ParseEvent(log, contractABI)
+    event, err := ParseEvent(vLog, contract.ABI)
     if err != nil {
-        fmt.Printf("Failed to parse event: %v\n", err)
-        return
+        log.Printf("Failed to parse event: %v", err)
+        return nil
     }
 
     if event.EventType == "" {
-        return // Skip if not a mint or burn event
+        return nil // Skip if not a transfer
+    }
+
+    ctx := context.Background()
+    timestamp, err := el.blockTime(ctx, event.BlockHash)
+    if err != nil {
+        return err
     }
 
     // Create transaction record
     tx := &database.Transaction{
         AccountAddress: event.Account,
+        FromAddress:   event.From,
+        ToAddress:     event.To,
         TokenAddress:  event.TokenAddress,
         Amount:        event.Amount.String(),
+        ChainID:       el.chainID,
         TxHash:        event.TransactionHash.Hex(),
+        LogIndex:      event.LogIndex,
         EventType:     event.EventType,
-        Timestamp:     time.Now(),
+        Timestamp:     timestamp,
+        IndexedAt:     time.Now(),
         BlockNumber:   event.BlockNumber,
         BlockHash:     event.BlockHash.Hex(),
+        Status:        el.status(event.BlockNumber),
     }
 
     // Save to MongoDB
-    ctx := context.Background()
     if err := el.db.SaveTransaction(ctx, tx); err != nil {
-        fmt.Printf("Failed to save transaction: %v", err)
-        return
-    }
-
-    fmt.Printf("\nNew %s event saved to MongoDB:\n", event.EventType)
-    fmt.Printf("Token Address: %s\n", event.TokenAddress)
-    fmt.Printf("Account: %s\n", event.Account)
-    fmt.Printf("Amount: %s\n", event.Amount.String())
-    fmt.Printf("Transaction Hash: %s\n", event.TransactionHash.Hex())
-    fmt.Printf("Block Number: %d\n", event.BlockNumber)
-    fmt.Printf("Block Hash: %s\n", event.BlockHash.Hex())
-}
\ No newline at end of file
+        log.Printf("Failed to save transaction: %v", err)
+        return nil
+    }
+
+    log.Printf("Saved %s of %s from %s to %s in tx %s (block %d)",
+        event.EventType, event.Amount, event.From, event.To, event.TransactionHash.Hex(), event.BlockNumber)
+
+    // Tokens pulled into a proxy mark a swap made through it
+    if proxy, ok := el.watched[common.HexToAddress(event.To)]; ok && proxy.Kind == database.ContractKindProxy && event.EventType == "Transfer" {
+        return el.processProxySwap(ctx, vLog, proxy)
+    }
+    return nil
+}
+
+func (el *EventListener) processPoolEvent(vLog types.Log, pool *database.Contract) error {
+    event, err := ParsePoolEvent(vLog)
+    if err != nil {
+        log.Printf("Failed to parse pool event: %v", err)
+        return nil
+    }
+
+    if event.EventType == "" {
+        return nil // Skip unknown pool events
+    }
+
+    event.Token0Address = pool.Token0Address
+    event.Token1Address = pool.Token1Address
+
+    ctx := context.Background()
+    timestamp, err := el.blockTime(ctx, event.BlockHash)
+    if err != nil {
+        return err
+    }
+
+    // Create pool transaction record
+    tx := &database.PoolTransaction{
+        PoolAddress:   event.PoolAddress,
+        Token0Address: event.Token0Address,
+        Token1Address: event.Token1Address,
+        EventType:     event.EventType,
+        Sender:        event.Sender,
+        Owner:         event.Owner,
+        Recipient:     event.Recipient,
+        Amount0:       bigIntString(event.Amount0),
+        Amount1:       bigIntString(event.Amount1),
+        SqrtPriceX96:  bigIntString(event.SqrtPriceX96),
+        Liquidity:     bigIntString(event.Liquidity),
+        Tick:          event.Tick,
+        TickLower:     event.TickLower,
+        TickUpper:     event.TickUpper,
+        Paid0:         bigIntString(event.Paid0),
+        Paid1:         bigIntString(event.Paid1),
+        ChainID:       el.chainID,
+        TxHash:        event.TransactionHash.Hex(),
+        LogIndex:      event.LogIndex,
+        BlockNumber:   event.BlockNumber,
+        BlockHash:     event.BlockHash.Hex(),
+        Timestamp:     timestamp,
+        IndexedAt:     time.Now(),
+        Status:        el.status(event.BlockNumber),
+    }
+
+    // Save to MongoDB
+    if err := el.db.SavePoolTransaction(ctx, tx); err != nil {
+        log.Printf("Failed to save pool transaction: %v", err)
+        return nil
+    }
+
+    log.Printf("Saved pool %s on %s: amount0 %s, amount1 %s in tx %s (block %d)",
+        event.EventType, event.PoolAddress, tx.Amount0, tx.Amount1, event.TransactionHash.Hex(), event.BlockNumber)
+    return nil
+}
+
+// bigIntString returns the decimal representation of v, or an empty string if v is nil
+func bigIntString(v *big.Int) string {
+    if v == nil {
+        return ""
+    }
+    return v.String()
+}
@@ -0,0 +1,182 @@
+package blockchain
+
+import (
+    "context"
+    "fmt"
+    "math/big"
+    "strings"
+
+    "github.com/ethereum/go-ethereum"
+    "github.com/ethereum/go-ethereum/accounts/abi"
+    "github.com/ethereum/go-ethereum/common"
+
+    "src/internal/database"
+)
+
+// uniswapV3PoolABI is the subset of the Uniswap V3 pool ABI the listener needs
+const uniswapV3PoolABI = `[
+    {"type":"function","name":"token0","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"address"}]},
+    {"type":"function","name":"token1","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"address"}]},
+    {"type":"function","name":"fee","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint24"}]},
+    {"type":"function","name":"tickSpacing","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"int24"}]},
+    {"type":"function","name":"slot0","stateMutability":"view","inputs":[],"outputs":[
+        {"name":"sqrtPriceX96","type":"uint160"},
+        {"name":"tick","type":"int24"},
+        {"name":"observationIndex","type":"uint16"},
+        {"name":"observationCardinality","type":"uint16"},
+        {"name":"observationCardinalityNext","type":"uint16"},
+        {"name":"feeProtocol","type":"uint8"},
+        {"name":"unlocked","type":"bool"}]},
+    {"type":"function","name":"observe","stateMutability":"view","inputs":[{"name":"secondsAgos","type":"uint32[]"}],"outputs":[
+        {"name":"tickCumulatives","type":"int56[]"},
+        {"name":"secondsPerLiquidityCumulativeX128s","type":"uint160[]"}]},
+    {"type":"event","name":"Mint","anonymous":false,"inputs":[
+        {"name":"sender","type":"address","indexed":false},
+        {"name":"owner","type":"address","indexed":true},
+        {"name":"tickLower","type":"int24","indexed":true},
+        {"name":"tickUpper","type":"int24","indexed":true},
+        {"name":"amount","type":"uint128","indexed":false},
+        {"name":"amount0","type":"uint256","indexed":false},
+        {"name":"amount1","type":"uint256","indexed":false}]},
+    {"type":"event","name":"Burn","anonymous":false,"inputs":[
+        {"name":"owner","type":"address","indexed":true},
+        {"name":"tickLower","type":"int24","indexed":true},
+        {"name":"tickUpper","type":"int24","indexed":true},
+        {"name":"amount","type":"uint128","indexed":false},
+        {"name":"amount0","type":"uint256","indexed":false},
+        {"name":"amount1","type":"uint256","indexed":false}]},
+    {"type":"event","name":"Swap","anonymous":false,"inputs":[
+        {"name":"sender","type":"address","indexed":true},
+        {"name":"recipient","type":"address","indexed":true},
+        {"name":"amount0","type":"int256","indexed":false},
+        {"name":"amount1","type":"int256","indexed":false},
+        {"name":"sqrtPriceX96","type":"uint160","indexed":false},
+        {"name":"liquidity","type":"uint128","indexed":false},
+        {"name":"tick","type":"int24","indexed":false}]},
+    {"type":"event","name":"Collect","anonymous":false,"inputs":[
+        {"name":"owner","type":"address","indexed":true},
+        {"name":"recipient","type":"address","indexed":false},
+        {"name":"tickLower","type":"int24","indexed":true},
+        {"name":"tickUpper","type":"int24","indexed":true},
+        {"name":"amount0","type":"uint128","indexed":false},
+        {"name":"amount1","type":"uint128","indexed":false}]},
+    {"type":"event","name":"Flash","anonymous":false,"inputs":[
+        {"name":"sender","type":"address","indexed":true},
+        {"name":"recipient","type":"address","indexed":true},
+        {"name":"amount0","type":"uint256","indexed":false},
+        {"name":"amount1","type":"uint256","indexed":false},
+        {"name":"paid0","type":"uint256","indexed":false},
+        {"name":"paid1","type":"uint256","indexed":false}]}
+]`
+
+var poolABI = mustParseABI(uniswapV3PoolABI)
+
+// ResolvePoolTokens reads token0() and token1() from the pool contract into its registry entry
+func ResolvePoolTokens(ctx context.Context, client ethereum.ContractCaller, pool *database.Contract) error {
+    poolAddress := common.HexToAddress(pool.Address)
+
+    token0, err := callAddress(ctx, client, poolAddress, "token0")
+    if err != nil {
+        return fmt.Errorf("failed to resolve token0 of pool %s: %v", pool.Address, err)
+    }
+
+    token1, err := callAddress(ctx, client, poolAddress, "token1")
+    if err != nil {
+        return fmt.Errorf("failed to resolve token1 of pool %s: %v", pool.Address, err)
+    }
+
+    pool.Token0Address = token0.Hex()
+    pool.Token1Address = token1.Hex()
+    return nil
+}
+
+// Slot0 reads the pool's current sqrtPriceX96 and tick
+func (c *Caller) Slot0(ctx context.Context, poolAddress string) (*big.Int, int, error) {
+    client, err := c.dial(ctx)
+    if err != nil {
+        return nil, 0, err
+    }
+
+    values, err := callContract(ctx, client, poolABI, common.HexToAddress(poolAddress), "slot0")
+    if err != nil {
+        return nil, 0, fmt.Errorf("failed to read slot0 of pool %s: %v", poolAddress, err)
+    }
+
+    return values[0].(*big.Int), int(values[1].(*big.Int).Int64()), nil
+}
+
+// Fee reads the pool's fee tier in hundredths of a basis point
+func (c *Caller) Fee(ctx context.Context, poolAddress string) (uint32, error) {
+    client, err := c.dial(ctx)
+    if err != nil {
+        return 0, err
+    }
+
+    values, err := callContract(ctx, client, poolABI, common.HexToAddress(poolAddress), "fee")
+    if err != nil {
+        return 0, fmt.Errorf("failed to read fee of pool %s: %v", poolAddress, err)
+    }
+
+    return uint32(values[0].(*big.Int).Uint64()), nil
+}
+
+// TickSpacing reads the spacing between the pool's initializable ticks
+func (c *Caller) TickSpacing(ctx context.Context, poolAddress string) (int, error) {
+    client, err := c.dial(ctx)
+    if err != nil {
+        return 0, err
+    }
+
+    values, err := callContract(ctx, client, poolABI, common.HexToAddress(poolAddress), "tickSpacing")
+    if err != nil {
+        return 0, fmt.Errorf("failed to read tick spacing of pool %s: %v", poolAddress, err)
+    }
+
+    return int(values[0].(*big.Int).Int64()), nil
+}
+
+// Observe reads the pool oracle's tick cumulatives for each of secondsAgos
+func (c *Caller) Observe(ctx context.Context, poolAddress string, secondsAgos []uint32) ([]*big.Int, error) {
+    client, err := c.dial(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    values, err := callContract(ctx, client, poolABI, common.HexToAddress(poolAddress), "observe", secondsAgos)
+    if err != nil {
+        return nil, fmt.Errorf("failed to observe pool %s: %v", poolAddress, err)
+    }
+
+    return values[0].([]*big.Int), nil
+}
+
+func callAddress(ctx context.Context, client ethereum.ContractCaller, contract common.Address, method string) (common.Address, error) {
+    values, err := callContract(ctx, client, poolABI, contract, method)
+    if err != nil {
+        return common.Address{}, err
+    }
+    return values[0].(common.Address), nil
+}
+
+// callContract packs a call to method, executes it against the latest block and unpacks the result
+func callContract(ctx context.Context, client ethereum.ContractCaller, parsed abi.ABI, contract common.Address, method string, args ...interface{}) ([]interface{}, error) {
+    data, err := parsed.Pack(method, args...)
+    if err != nil {
+        return nil, err
+    }
+
+    result, err := client.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: data}, nil)
+    if err != nil {
+        return nil, err
+    }
+
+    return parsed.Unpack(method, result)
+}
+
+func mustParseABI(definition string) abi.ABI {
+    parsed, err := abi.JSON(strings.NewReader(definition))
+    if err != nil {
+        panic(fmt.Sprintf("invalid ABI definition: %v", err))
+    }
+    return parsed
+}
@@ -1,57 +1,160 @@
 package blockchain
 
 import (
+    "fmt"
+    "math/big"
+
     "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/common/math"
     "github.com/ethereum/go-ethereum/core/types"
-    "math/big"
 )
 
+// Uniswap V3 pool event signatures
+var PoolEventTopics = []common.Hash{
+    common.HexToHash("0x7a53080ba414158be7ec69b987b5fb7d07dee101fe85488f0853ae16239d0bde"), // Mint
+    common.HexToHash("0x0c396cd989a39f4459b5fa1aed6a9a8dcdbc45908acfd67e028cd568da98982c"), // Burn
+    common.HexToHash("0xc42079f94a6350d7e6235f29174924f928cc2ac818eb64fed8004e115fbcca67"), // Swap
+    common.HexToHash("0x70935338e69775456a85ddef226c395fb668b63fa0115f5f20610b388e6ca9c0"), // Collect
+    common.HexToHash("0xbdbdb71d7860376ba52b25a5028beea23581364a40522f6bcfb86bb1f2dca633"), // Flash
+}
+
 type PoolEvent struct {
     TransactionHash common.Hash
     BlockNumber     uint64
     BlockHash       common.Hash
+    LogIndex        uint
     EventType       string    // "Mint", "Burn", "Swap", "Collect", "Flash"
     PoolAddress     string
     Token0Address   string
     Token1Address   string
     Sender         string
+    Owner          string    // Position owner for Mint, Burn and Collect
     Recipient      string
-    Amount0        *big.Int
+    Amount0        *big.Int  // Signed for Swap, unsigned otherwise
     Amount1        *big.Int
-    SqrtPriceX96   *big.Int
-    Liquidity      *big.Int
-    Tick           int
+    SqrtPriceX96   *big.Int  // Swap only
+    Liquidity      *big.Int  // Pool liquidity for Swap, position liquidity delta for Mint and Burn
+    Tick           int       // Swap only
+    TickLower      int       // Mint, Burn and Collect
+    TickUpper      int
+    Paid0          *big.Int  // Flash fees paid
+    Paid1          *big.Int
     Timestamp      uint64
 }
 
-func ParsePoolEvent(log types.Log, contractABI string) (*PoolEvent, error) {
+func ParsePoolEvent(log types.Log) (*PoolEvent, error) {
     event := &PoolEvent{
         TransactionHash: log.TxHash,
         BlockNumber:     log.BlockNumber,
         BlockHash:       log.BlockHash,
+        LogIndex:        log.Index,
         PoolAddress:     log.Address.Hex(),
     }
 
+    if len(log.Topics) == 0 {
+        return event, nil
+    }
+
     // Parse based on event signature
     switch log.Topics[0].Hex() {
     case "0x7a53080ba414158be7ec69b987b5fb7d07dee101fe85488f0853ae16239d0bde": // Mint
+        // Mint(address sender, address indexed owner, int24 indexed tickLower, int24 indexed tickUpper,
+        //      uint128 amount, uint256 amount0, uint256 amount1)
+        data, err := unpackPoolEvent("Mint", log, 4)
+        if err != nil {
+            return nil, err
+        }
         event.EventType = "Mint"
-        event.Sender = common.HexToAddress(log.Topics[1].Hex()).Hex()
-        // Parse other mint-specific data...
+        event.Owner = common.HexToAddress(log.Topics[1].Hex()).Hex()
+        event.TickLower = topicToInt(log.Topics[2])
+        event.TickUpper = topicToInt(log.Topics[3])
+        event.Sender = data["sender"].(common.Address).Hex()
+        event.Liquidity = data["amount"].(*big.Int)
+        event.Amount0 = data["amount0"].(*big.Int)
+        event.Amount1 = data["amount1"].(*big.Int)
 
     case "0x0c396cd989a39f4459b5fa1aed6a9a8dcdbc45908acfd67e028cd568da98982c": // Burn
+        // Burn(address indexed owner, int24 indexed tickLower, int24 indexed tickUpper,
+        //      uint128 amount, uint256 amount0, uint256 amount1)
+        data, err := unpackPoolEvent("Burn", log, 4)
+        if err != nil {
+            return nil, err
+        }
         event.EventType = "Burn"
-        event.Sender = common.HexToAddress(log.Topics[1].Hex()).Hex()
-        // Parse other burn-specific data...
+        event.Owner = common.HexToAddress(log.Topics[1].Hex()).Hex()
+        event.Sender = event.Owner
+        event.TickLower = topicToInt(log.Topics[2])
+        event.TickUpper = topicToInt(log.Topics[3])
+        event.Liquidity = data["amount"].(*big.Int)
+        event.Amount0 = data["amount0"].(*big.Int)
+        event.Amount1 = data["amount1"].(*big.Int)
 
     case "0xc42079f94a6350d7e6235f29174924f928cc2ac818eb64fed8004e115fbcca67": // Swap
+        // Swap(address indexed sender, address indexed recipient, int256 amount0, int256 amount1,
+        //      uint160 sqrtPriceX96, uint128 liquidity, int24 tick)
+        data, err := unpackPoolEvent("Swap", log, 3)
+        if err != nil {
+            return nil, err
+        }
         event.EventType = "Swap"
         event.Sender = common.HexToAddress(log.Topics[1].Hex()).Hex()
         event.Recipient = common.HexToAddress(log.Topics[2].Hex()).Hex()
-        // Parse other swap-specific data...
+        event.Amount0 = data["amount0"].(*big.Int)
+        event.Amount1 = data["amount1"].(*big.Int)
+        event.SqrtPriceX96 = data["sqrtPriceX96"].(*big.Int)
+        event.Liquidity = data["liquidity"].(*big.Int)
+        event.Tick = int(data["tick"].(*big.Int).Int64())
+
+    case "0x70935338e69775456a85ddef226c395fb668b63fa0115f5f20610b388e6ca9c0": // Collect
+        // Collect(address indexed owner, address recipient, int24 indexed tickLower, int24 indexed tickUpper,
+        //         uint128 amount0, uint128 amount1)
+        data, err := unpackPoolEvent("Collect", log, 4)
+        if err != nil {
+            return nil, err
+        }
+        event.EventType = "Collect"
+        event.Owner = common.HexToAddress(log.Topics[1].Hex()).Hex()
+        event.Sender = event.Owner
+        event.TickLower = topicToInt(log.Topics[2])
+        event.TickUpper = topicToInt(log.Topics[3])
+        event.Recipient = data["recipient"].(common.Address).Hex()
+        event.Amount0 = data["amount0"].(*big.Int)
+        event.Amount1 = data["amount1"].(*big.Int)
 
-    // Add cases for Collect and Flash events...
+    case "0xbdbdb71d7860376ba52b25a5028beea23581364a40522f6bcfb86bb1f2dca633": // Flash
+        // Flash(address indexed sender, address indexed recipient, uint256 amount0, uint256 amount1,
+        //       uint256 paid0, uint256 paid1)
+        data, err := unpackPoolEvent("Flash", log, 3)
+        if err != nil {
+            return nil, err
+        }
+        event.EventType = "Flash"
+        event.Sender = common.HexToAddress(log.Topics[1].Hex()).Hex()
+        event.Recipient = common.HexToAddress(log.Topics[2].Hex()).Hex()
+        event.Amount0 = data["amount0"].(*big.Int)
+        event.Amount1 = data["amount1"].(*big.Int)
+        event.Paid0 = data["paid0"].(*big.Int)
+        event.Paid1 = data["paid1"].(*big.Int)
     }
 
     return event, nil
-}
\ No newline at end of file
+}
+
+// unpackPoolEvent decodes the non-indexed data of a pool event after checking
+// that the log carries the expected number of topics
+func unpackPoolEvent(name string, log types.Log, topics int) (map[string]interface{}, error) {
+    if len(log.Topics) != topics {
+        return nil, fmt.Errorf("invalid %s log: expected %d topics, got %d", name, topics, len(log.Topics))
+    }
+
+    data := make(map[string]interface{})
+    if err := poolABI.UnpackIntoMap(data, name, log.Data); err != nil {
+        return nil, fmt.Errorf("failed to decode %s data: %v", name, err)
+    }
+    return data, nil
+}
+
+// topicToInt decodes an indexed signed integer (e.g. int24 tick) from a topic
+func topicToInt(topic common.Hash) int {
+    return int(math.S256(new(big.Int).SetBytes(topic.Bytes())).Int64())
+}
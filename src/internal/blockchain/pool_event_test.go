@@ -0,0 +1,252 @@
+package blockchain
+
+import (
+    "fmt"
+    "strings"
+    "testing"
+
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/common/hexutil"
+    "github.com/ethereum/go-ethereum/core/types"
+    "github.com/ethereum/go-ethereum/crypto"
+)
+
+// The logs below are synthetic, encoded by hand in the layout UniswapV3Pool
+// emits. Their values are kept consistent with each other: amounts match the
+// position ranges at the pool price, and every sqrtPriceX96 lies in its tick.
+var (
+    mintTopic    = "0x7a53080ba414158be7ec69b987b5fb7d07dee101fe85488f0853ae16239d0bde"
+    burnTopic    = "0x0c396cd989a39f4459b5fa1aed6a9a8dcdbc45908acfd67e028cd568da98982c"
+    swapTopic    = "0xc42079f94a6350d7e6235f29174924f928cc2ac818eb64fed8004e115fbcca67"
+    collectTopic = "0x70935338e69775456a85ddef226c395fb668b63fa0115f5f20610b388e6ca9c0"
+    flashTopic   = "0xbdbdb71d7860376ba52b25a5028beea23581364a40522f6bcfb86bb1f2dca633"
+
+    routerTopic    = "0x000000000000000000000000e592427a0aece92de3edee1f18e0157c05861564"
+    positionsTopic = "0x000000000000000000000000c36442b4a4522e871399cd717abdd847ab11fe88"
+    traderTopic    = "0x00000000000000000000000070997970c51812dc3a010c7d01b50e0d17dc79c8"
+
+    minTickTopic    = "0xfffffffffffffffffffffffffffffffffffffffffffffffffffffffffff2764c" // -887220
+    maxTickTopic    = "0x00000000000000000000000000000000000000000000000000000000000d89b4" // 887220
+    tick194400Topic = "0x000000000000000000000000000000000000000000000000000000000002f760"
+    tick196620Topic = "0x000000000000000000000000000000000000000000000000000000000003000c"
+)
+
+var (
+    router    = common.HexToAddress("0xe592427a0aece92de3edee1f18e0157c05861564").Hex()
+    positions = common.HexToAddress("0xc36442b4a4522e871399cd717abdd847ab11fe88").Hex()
+    trader    = common.HexToAddress("0x70997970c51812dc3a010c7d01b50e0d17dc79c8").Hex()
+
+    // A USDC/WETH 0.3% pool near tick 195500 and a DAI/USDC pool near tick -276326
+    usdcWethPool = common.HexToAddress("0x8ad599c3a0ff1de082011efddc58f1908eb6e6d8")
+    daiUsdcPool  = common.HexToAddress("0x6c6bc977e13df9b0de53b251522280bb72383700")
+)
+
+// poolLog builds a log of pool at the given position. The transaction hash is
+// derived from the position, so every fixture has its own.
+func poolLog(pool common.Address, block uint64, index uint, data string, topics ...string) types.Log {
+    log := types.Log{
+        Address:     pool,
+        Data:        hexutil.MustDecode(data),
+        BlockNumber: block,
+        TxHash:      crypto.Keccak256Hash([]byte(fmt.Sprintf("%d/%d", block, index))),
+        Index:       index,
+    }
+    for _, topic := range topics {
+        log.Topics = append(log.Topics, common.HexToHash(topic))
+    }
+    return log
+}
+
+// wantPoolEvent is the decoded event expected from a log, with amounts in decimal
+type wantPoolEvent struct {
+    eventType string
+    sender    string
+    owner     string
+    recipient string
+    amount0   string
+    amount1   string
+    sqrtPrice string
+    liquidity string
+    tick      int
+    tickLower int
+    tickUpper int
+    paid0     string
+    paid1     string
+}
+
+func TestParsePoolEvent(t *testing.T) {
+    tests := []struct {
+        name string
+        log  types.Log
+        want wantPoolEvent
+    }{
+        {
+            name: "mint over the full range through the position manager",
+            log: poolLog(usdcWethPool, 20184311, 143, "0x000000000000000000000000c36442b4a4522e871399cd717abdd847ab11fe8800000000000000000000000000000000000000000000000000004faef40d40430000000000000000000000000000000000000000000000000000000128ffbe390000000000000000000000000000000000000000000000001560f11311ee12e9",
+                mintTopic, positionsTopic, minTickTopic, maxTickTopic),
+            want: wantPoolEvent{
+                eventType: "Mint",
+                sender:    positions,
+                owner:     positions,
+                amount0:   "4982816313",
+                amount1:   "1540496136768197353",
+                liquidity: "87612837412931",
+                tickLower: -887220,
+                tickUpper: 887220,
+            },
+        },
+        {
+            name: "burn of a range around the current tick",
+            log: poolLog(usdcWethPool, 20184502, 88, "0x0000000000000000000000000000000000000000000000000006fa898eb5c37a000000000000000000000000000000000000000000000000000000016984e27200000000000000000000000000000000000000000000000019babb856d2ca091",
+                burnTopic, positionsTopic, tick194400Topic, tick196620Topic),
+            want: wantPoolEvent{
+                eventType: "Burn",
+                sender:    positions,
+                owner:     positions,
+                amount0:   "6065283698",
+                amount1:   "1854000378353066129",
+                liquidity: "1964318572004218",
+                tickLower: 194400,
+                tickUpper: 196620,
+            },
+        },
+        {
+            name: "collect of the burned range with its fees",
+            log: poolLog(usdcWethPool, 20184502, 90, "0x00000000000000000000000070997970c51812dc3a010c7d01b50e0d17dc79c80000000000000000000000000000000000000000000000000000000169a1025600000000000000000000000000000000000000000000000019bcbe67bc8dadd6",
+                collectTopic, positionsTopic, tick194400Topic, tick196620Topic),
+            want: wantPoolEvent{
+                eventType: "Collect",
+                sender:    positions,
+                owner:     positions,
+                recipient: trader,
+                amount0:   "6067126870",
+                amount1:   "1854566499324112342",
+                tickLower: 194400,
+                tickUpper: 196620,
+            },
+        },
+        {
+            name: "swap of WETH in for USDC out",
+            log: poolLog(usdcWethPool, 20184517, 12, "0xffffffffffffffffffffffffffffffffffffffffffffffffffffffff3e001b520000000000000000000000000000000000000000000000000de0b6b3a764000000000000000000000000000000000000000044aefee1299391e840dcaede11990000000000000000000000000000000000000000000000000040b5d537bdb314000000000000000000000000000000000000000000000000000000000002fbaf",
+                swapTopic, routerTopic, traderTopic),
+            want: wantPoolEvent{
+                eventType: "Swap",
+                sender:    router,
+                recipient: trader,
+                amount0:   "-3254772910",
+                amount1:   "1000000000000000000",
+                sqrtPrice: "1393068434722938829409847563129241",
+                liquidity: "18214325877322516",
+                tick:      195503,
+            },
+        },
+        {
+            name: "swap of DAI in for USDC out at a negative tick",
+            log: poolLog(daiUsdcPool, 20185733, 201, "0x00000000000000000000000000000000000000000000000d8d726b7177a80000fffffffffffffffffffffffffffffffffffffffffffffffffffffffff11a8b530000000000000000000000000000000000000000000010c690a0a88ba1440aca00000000000000000000000000000000000000000000000000062a58d9f0a15bfffffffffffffffffffffffffffffffffffffffffffffffffffffffffffbc89a",
+                swapTopic, routerTopic, traderTopic),
+            want: wantPoolEvent{
+                eventType: "Swap",
+                sender:    router,
+                recipient: trader,
+                amount0:   "250000000000000000000",
+                amount1:   "-249918637",
+                sqrtPrice: "79220740567364209478346",
+                liquidity: "1735410962178395",
+                tick:      -276326,
+            },
+        },
+        {
+            name: "flash paying fees in token0 only",
+            log: poolLog(usdcWethPool, 20185020, 3, "0x000000000000000000000000000000000000000000000000000000009502f900000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000007270e00000000000000000000000000000000000000000000000000000000000000000",
+                flashTopic, traderTopic, traderTopic),
+            want: wantPoolEvent{
+                eventType: "Flash",
+                sender:    trader,
+                recipient: trader,
+                amount0:   "2500000000",
+                amount1:   "0",
+                paid0:     "7500000",
+                paid1:     "0",
+            },
+        },
+        {
+            name: "unknown event",
+            log:  poolLog(usdcWethPool, 20185021, 0, "0x", "0x1c411e9a96e071241c2f21f7726b17ae89e3cab4c78be50e062b03a9fffbbad1"),
+            want: wantPoolEvent{},
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            event, err := ParsePoolEvent(tt.log)
+            if err != nil {
+                t.Fatalf("ParsePoolEvent() error = %v", err)
+            }
+
+            got := wantPoolEvent{
+                eventType: event.EventType,
+                sender:    event.Sender,
+                owner:     event.Owner,
+                recipient: event.Recipient,
+                amount0:   bigIntString(event.Amount0),
+                amount1:   bigIntString(event.Amount1),
+                sqrtPrice: bigIntString(event.SqrtPriceX96),
+                liquidity: bigIntString(event.Liquidity),
+                tick:      event.Tick,
+                tickLower: event.TickLower,
+                tickUpper: event.TickUpper,
+                paid0:     bigIntString(event.Paid0),
+                paid1:     bigIntString(event.Paid1),
+            }
+            if got != tt.want {
+                t.Errorf("ParsePoolEvent() =\n%+v\nwant\n%+v", got, tt.want)
+            }
+
+            if event.PoolAddress != tt.log.Address.Hex() || event.TransactionHash != tt.log.TxHash ||
+                event.BlockNumber != tt.log.BlockNumber || event.LogIndex != tt.log.Index {
+                t.Errorf("ParsePoolEvent() did not copy the log position: %+v", event)
+            }
+        })
+    }
+}
+
+func TestParsePoolEventErrors(t *testing.T) {
+    tests := []struct {
+        name string
+        log  types.Log
+        want string
+    }{
+        {
+            name: "swap without the recipient topic",
+            log:  poolLog(usdcWethPool, 1, 0, "0x", swapTopic, routerTopic),
+            want: "invalid Swap log: expected 3 topics, got 2",
+        },
+        {
+            name: "mint missing tickUpper",
+            log:  poolLog(usdcWethPool, 1, 0, "0x", mintTopic, positionsTopic, minTickTopic),
+            want: "invalid Mint log: expected 4 topics, got 3",
+        },
+        {
+            name: "collect with an extra topic",
+            log:  poolLog(usdcWethPool, 1, 0, "0x", collectTopic, positionsTopic, tick194400Topic, tick196620Topic, traderTopic),
+            want: "invalid Collect log: expected 4 topics, got 5",
+        },
+        {
+            name: "flash with truncated data",
+            log:  poolLog(usdcWethPool, 1, 0, "0x0000000000000000000000000000000000000000000000000000000000000001", flashTopic, routerTopic, traderTopic),
+            want: "failed to decode Flash data",
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            event, err := ParsePoolEvent(tt.log)
+            if err == nil {
+                t.Fatalf("ParsePoolEvent() = %+v, want error %q", event, tt.want)
+            }
+            if !strings.Contains(err.Error(), tt.want) {
+                t.Errorf("ParsePoolEvent() error = %q, want %q", err, tt.want)
+            }
+        })
+    }
+}
@@ -0,0 +1,216 @@
+package blockchain
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "time"
+
+    "github.com/ethereum/go-ethereum"
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/core/types"
+    "github.com/ethereum/go-ethereum/crypto"
+
+    "src/internal/database"
+)
+
+// EIP-1967 proxy event signatures
+var (
+    UpgradedTopic       = common.HexToHash("0xbc7cd75a20ee27fd9adebab32041f755214dbc6bffa90cc0225b39da2e5c2d3b")
+    AdminChangedTopic   = common.HexToHash("0x7e644d79422f17c01e4894b5f4f588d331ebfa28653d42ae832dc59e38c9798f")
+    BeaconUpgradedTopic = common.HexToHash("0x1cf3b03a6cf19fa2baba4df148e9dcabedea7f8a5c07840e207e5c089be95d3e")
+)
+
+// EIP-1967 storage slots, keccak256 of the slot name minus one
+var (
+    implementationSlot = common.HexToHash("0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc")
+    adminSlot          = common.HexToHash("0xb53127684a568b3173ae13b9f8a6016e243e63b6e8ee1178d6a717850b5d6103")
+    beaconSlot         = common.HexToHash("0xa3f0ad74e5423aebfd80d3ef4346578335a9a72aeaee59ff6cb3582b35133d50")
+)
+
+// ProxyEventTopics are the events indexed on registered proxies
+var ProxyEventTopics = []common.Hash{UpgradedTopic, AdminChangedTopic, BeaconUpgradedTopic}
+
+const proxyEventsABI = `[
+    {"type":"event","name":"AdminChanged","anonymous":false,"inputs":[
+        {"name":"previousAdmin","type":"address","indexed":false},
+        {"name":"newAdmin","type":"address","indexed":false}]},
+    {"type":"function","name":"version","stateMutability":"pure","inputs":[],"outputs":[{"name":"","type":"string"}]}
+]`
+
+var proxyABI = mustParseABI(proxyEventsABI)
+
+type ProxyEvent struct {
+    TransactionHash common.Hash
+    BlockNumber     uint64
+    BlockHash       common.Hash
+    LogIndex        uint
+    EventType       string // "Upgraded", "AdminChanged" or "BeaconUpgraded"
+    ProxyAddress    string
+    Implementation  string // Upgraded only
+    PreviousAdmin   string // AdminChanged only
+    NewAdmin        string
+    Beacon          string // BeaconUpgraded only
+}
+
+// ParseProxyEvent decodes an EIP-1967 proxy log, returning nil for any other event
+func ParseProxyEvent(log types.Log) (*ProxyEvent, error) {
+    if len(log.Topics) == 0 {
+        return nil, nil
+    }
+
+    event := &ProxyEvent{
+        TransactionHash: log.TxHash,
+        BlockNumber:     log.BlockNumber,
+        BlockHash:       log.BlockHash,
+        LogIndex:        log.Index,
+        ProxyAddress:    log.Address.Hex(),
+    }
+
+    switch log.Topics[0] {
+    case UpgradedTopic:
+        // Upgraded(address indexed implementation)
+        if len(log.Topics) != 2 {
+            return nil, fmt.Errorf("invalid Upgraded log: expected 2 topics, got %d", len(log.Topics))
+        }
+        event.EventType = "Upgraded"
+        event.Implementation = common.HexToAddress(log.Topics[1].Hex()).Hex()
+
+    case AdminChangedTopic:
+        // AdminChanged(address previousAdmin, address newAdmin)
+        data := make(map[string]interface{})
+        if err := proxyABI.UnpackIntoMap(data, "AdminChanged", log.Data); err != nil {
+            return nil, fmt.Errorf("failed to decode AdminChanged data: %v", err)
+        }
+        event.EventType = "AdminChanged"
+        event.PreviousAdmin = data["previousAdmin"].(common.Address).Hex()
+        event.NewAdmin = data["newAdmin"].(common.Address).Hex()
+
+    case BeaconUpgradedTopic:
+        // BeaconUpgraded(address indexed beacon)
+        if len(log.Topics) != 2 {
+            return nil, fmt.Errorf("invalid BeaconUpgraded log: expected 2 topics, got %d", len(log.Topics))
+        }
+        event.EventType = "BeaconUpgraded"
+        event.Beacon = common.HexToAddress(log.Topics[1].Hex()).Hex()
+
+    default:
+        return nil, nil
+    }
+
+    return event, nil
+}
+
+// implementationVersion calls version() on an implementation. Implementations
+// without the function (e.g. UniswapProxy V1) report an empty version.
+func implementationVersion(ctx context.Context, client ethereum.ContractCaller, implementation string) string {
+    values, err := callContract(ctx, client, proxyABI, common.HexToAddress(implementation), "version")
+    if err != nil {
+        return ""
+    }
+    return values[0].(string)
+}
+
+func (el *EventListener) processProxyEvent(vLog types.Log) error {
+    event, err := ParseProxyEvent(vLog)
+    if err != nil {
+        log.Printf("Failed to parse proxy event: %v", err)
+        return nil
+    }
+    if event == nil {
+        return nil
+    }
+
+    ctx := context.Background()
+    timestamp, err := el.blockTime(ctx, event.BlockHash)
+    if err != nil {
+        return err
+    }
+
+    record := &database.ProxyEvent{
+        ProxyAddress:   event.ProxyAddress,
+        EventType:      event.EventType,
+        Implementation: event.Implementation,
+        PreviousAdmin:  event.PreviousAdmin,
+        NewAdmin:       event.NewAdmin,
+        Beacon:         event.Beacon,
+        ChainID:        el.chainID,
+        TxHash:         event.TransactionHash.Hex(),
+        LogIndex:       event.LogIndex,
+        BlockNumber:    event.BlockNumber,
+        BlockHash:      event.BlockHash.Hex(),
+        Timestamp:      timestamp,
+        IndexedAt:      time.Now(),
+        Status:         el.status(event.BlockNumber),
+    }
+    if event.Implementation != "" {
+        record.Version = implementationVersion(ctx, el.client, event.Implementation)
+    }
+
+    if err := el.db.SaveProxyEvent(ctx, record); err != nil {
+        log.Printf("Failed to save proxy event: %v", err)
+        return nil
+    }
+
+    switch event.EventType {
+    case "Upgraded":
+        log.Printf("Proxy %s upgraded to %s (version %q) at block %d", event.ProxyAddress, event.Implementation, record.Version, event.BlockNumber)
+    case "AdminChanged":
+        log.Printf("Proxy %s admin changed from %s to %s at block %d", event.ProxyAddress, event.PreviousAdmin, event.NewAdmin, event.BlockNumber)
+    case "BeaconUpgraded":
+        log.Printf("Proxy %s beacon set to %s at block %d", event.ProxyAddress, event.Beacon, event.BlockNumber)
+    }
+    return nil
+}
+
+// ProxySlots reads the implementation, admin and beacon addresses stored in a
+// proxy's EIP-1967 slots at the latest block. Unset slots read as the zero address.
+func (c *Caller) ProxySlots(ctx context.Context, proxyAddress string) (implementation, admin, beacon string, err error) {
+    client, err := c.dial(ctx)
+    if err != nil {
+        return "", "", "", err
+    }
+
+    address := common.HexToAddress(proxyAddress)
+    values := make([]string, 0, 3)
+    for _, slot := range []common.Hash{implementationSlot, adminSlot, beaconSlot} {
+        word, err := client.StorageAt(ctx, address, slot, nil)
+        if err != nil {
+            return "", "", "", fmt.Errorf("failed to read slot %s of %s: %v", slot.Hex(), address.Hex(), err)
+        }
+        values = append(values, common.BytesToAddress(word).Hex())
+    }
+    return values[0], values[1], values[2], nil
+}
+
+// CodeHash returns the keccak256 hash of the runtime bytecode at address, and
+// the zero hash when there is no code
+func (c *Caller) CodeHash(ctx context.Context, address string) (string, error) {
+    client, err := c.dial(ctx)
+    if err != nil {
+        return "", err
+    }
+
+    code, err := client.CodeAt(ctx, common.HexToAddress(address), nil)
+    if err != nil {
+        return "", fmt.Errorf("failed to get code of %s: %v", address, err)
+    }
+    if len(code) == 0 {
+        return common.Hash{}.Hex(), nil
+    }
+    return crypto.Keccak256Hash(code).Hex(), nil
+}
+
+// Version calls version() on a contract, failing when it has no such function
+func (c *Caller) Version(ctx context.Context, address string) (string, error) {
+    client, err := c.dial(ctx)
+    if err != nil {
+        return "", err
+    }
+
+    values, err := callContract(ctx, client, proxyABI, common.HexToAddress(address), "version")
+    if err != nil {
+        return "", fmt.Errorf("failed to get version of %s: %v", address, err)
+    }
+    return values[0].(string), nil
+}
@@ -0,0 +1,178 @@
+package blockchain
+
+import (
+    "context"
+    "log"
+    "math/big"
+    "time"
+
+    "github.com/ethereum/go-ethereum"
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/core/types"
+
+    "src/internal/database"
+)
+
+var transferTopic = common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+
+const proxySwapABI = `[
+    {"type":"function","name":"swapExactInputSingle","stateMutability":"nonpayable","inputs":[
+        {"name":"amountIn","type":"uint256"}],"outputs":[{"name":"amountOut","type":"uint256"}]},
+    {"type":"function","name":"swapExactOutputSingle","stateMutability":"nonpayable","inputs":[
+        {"name":"amountOut","type":"uint256"},{"name":"amountInMaximum","type":"uint256"}],"outputs":[{"name":"amountIn","type":"uint256"}]}
+]`
+
+var proxySwapsABI = mustParseABI(proxySwapABI)
+
+// Swap modes of a proxy swap
+const (
+    SwapModeExactIn  = "exact-in"
+    SwapModeExactOut = "exact-out"
+)
+
+// processProxySwap decodes a swap made through a UniswapProxy. Both swap
+// functions start by pulling token1 from the caller into the proxy, so the
+// Transfer log into the proxy identifies the transaction; this requires the
+// proxy's input token to be a registered ERC-20. The swap is rebuilt from the
+// calldata and the transaction's Transfer and pool Swap logs.
+func (el *EventListener) processProxySwap(ctx context.Context, pull types.Log, proxy *database.Contract) error {
+    proxyAddress := common.HexToAddress(proxy.Address)
+
+    tx, _, err := el.client.TransactionByHash(ctx, pull.TxHash)
+    if err != nil {
+        log.Printf("Failed to get proxy transaction %s: %v", pull.TxHash.Hex(), err)
+        return nil
+    }
+    if tx.To() == nil || *tx.To() != proxyAddress || len(tx.Data()) < 4 {
+        return nil // A plain transfer to the proxy, or a call through another contract
+    }
+
+    method, err := proxySwapsABI.MethodById(tx.Data()[:4])
+    if err != nil {
+        return nil // Not a swap, e.g. initialize()
+    }
+    args, err := method.Inputs.Unpack(tx.Data()[4:])
+    if err != nil {
+        log.Printf("Failed to decode %s calldata in %s: %v", method.Name, pull.TxHash.Hex(), err)
+        return nil
+    }
+
+    user, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+    if err != nil {
+        log.Printf("Failed to recover sender of %s: %v", pull.TxHash.Hex(), err)
+        return nil
+    }
+
+    receipt, err := el.client.TransactionReceipt(ctx, pull.TxHash)
+    if err != nil {
+        log.Printf("Failed to get receipt of %s: %v", pull.TxHash.Hex(), err)
+        return nil
+    }
+
+    timestamp, err := el.blockTime(ctx, pull.BlockHash)
+    if err != nil {
+        return err
+    }
+
+    tokenIn := pull.Address
+    swap := &database.ProxySwap{
+        ProxyAddress: proxyAddress.Hex(),
+        User:         user.Hex(),
+        TokenIn:      tokenIn.Hex(),
+        ChainID:      el.chainID,
+        TxHash:       pull.TxHash.Hex(),
+        LogIndex:     pull.Index,
+        BlockNumber:  pull.BlockNumber,
+        BlockHash:    pull.BlockHash.Hex(),
+        Timestamp:    timestamp,
+        IndexedAt:    time.Now(),
+        Status:       el.status(pull.BlockNumber),
+    }
+
+    pulled := new(big.Int).SetBytes(pull.Data)
+    refund := new(big.Int)
+    amountOut := new(big.Int)
+    for _, l := range receipt.Logs {
+        switch {
+        case isTransfer(l) && l.Address == tokenIn && transferParty(l, 1) == proxyAddress && transferParty(l, 2) == user:
+            // swapExactOutputSingle returns what the router did not spend
+            refund.Add(refund, new(big.Int).SetBytes(l.Data))
+        case isTransfer(l) && l.Address != tokenIn && transferParty(l, 2) == user:
+            swap.TokenOut = l.Address.Hex()
+            amountOut.Add(amountOut, new(big.Int).SetBytes(l.Data))
+        case len(l.Topics) == 3 && l.Topics[0] == PoolEventTopics[2] && swap.PoolAddress == "":
+            if event, err := ParsePoolEvent(*l); err == nil && event.Recipient == user.Hex() {
+                swap.PoolAddress = event.PoolAddress
+            }
+        }
+    }
+
+    switch method.Name {
+    case "swapExactInputSingle":
+        swap.Mode = SwapModeExactIn
+    case "swapExactOutputSingle":
+        swap.Mode = SwapModeExactOut
+        swap.AmountOutRequested = args[0].(*big.Int).String()
+        swap.AmountInMaximum = args[1].(*big.Int).String()
+    }
+    swap.AmountIn = new(big.Int).Sub(pulled, refund).String()
+    swap.AmountOut = amountOut.String()
+    swap.Refund = refund.String()
+
+    swap.Implementation, swap.Version = el.implementationAt(ctx, proxyAddress, pull)
+
+    if err := el.db.SaveProxySwap(ctx, swap); err != nil {
+        log.Printf("Failed to save proxy swap: %v", err)
+        return nil
+    }
+
+    log.Printf("Proxy swap %s by %s through %s (version %q): %s %s in, %s %s out, %s refunded",
+        swap.Mode, swap.User, swap.ProxyAddress, swap.Version, swap.AmountIn, swap.TokenIn, swap.AmountOut, swap.TokenOut, swap.Refund)
+    return nil
+}
+
+// implementationAt returns the implementation the proxy delegated to when the
+// log was emitted, from the indexed Upgraded events, and its version. Without
+// indexed upgrades, version() is called through the proxy at that block.
+func (el *EventListener) implementationAt(ctx context.Context, proxy common.Address, at types.Log) (string, string) {
+    events, err := el.db.GetProxyEvents(ctx, proxy.Hex())
+    if err != nil {
+        log.Printf("Failed to get proxy events of %s: %v", proxy.Hex(), err)
+    }
+
+    var upgrade *database.ProxyEvent
+    for _, event := range events {
+        if event.BlockNumber > at.BlockNumber || (event.BlockNumber == at.BlockNumber && event.LogIndex > at.Index) {
+            break
+        }
+        if event.EventType == "Upgraded" {
+            upgrade = event
+        }
+    }
+    if upgrade != nil {
+        return upgrade.Implementation, upgrade.Version
+    }
+
+    data, err := proxyABI.Pack("version")
+    if err != nil {
+        return "", ""
+    }
+    result, err := el.client.CallContract(ctx, ethereum.CallMsg{To: &proxy, Data: data}, new(big.Int).SetUint64(at.BlockNumber))
+    if err != nil {
+        return "", "" // No version(), e.g. UniswapProxy V1
+    }
+    values, err := proxyABI.Unpack("version", result)
+    if err != nil {
+        return "", ""
+    }
+    return "", values[0].(string)
+}
+
+func isTransfer(l *types.Log) bool {
+    return len(l.Topics) == 3 && l.Topics[0] == transferTopic
+}
+
+// transferParty returns the from (1) or to (2) address of a Transfer log
+func transferParty(l *types.Log, topic int) common.Address {
+    return common.BytesToAddress(l.Topics[topic].Bytes())
+}
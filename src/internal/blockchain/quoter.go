@@ -0,0 +1,102 @@
+package blockchain
+
+import (
+    "context"
+    "fmt"
+    "math/big"
+
+    "github.com/ethereum/go-ethereum/common"
+)
+
+// quoterV2ABI covers the single-pool quotes of the Uniswap V3 periphery QuoterV2.
+// The functions are not view, they revert internally, but can be executed with eth_call.
+const quoterV2ABI = `[
+    {"type":"function","name":"quoteExactInputSingle","stateMutability":"nonpayable","inputs":[
+        {"name":"params","type":"tuple","components":[
+            {"name":"tokenIn","type":"address"},
+            {"name":"tokenOut","type":"address"},
+            {"name":"amountIn","type":"uint256"},
+            {"name":"fee","type":"uint24"},
+            {"name":"sqrtPriceLimitX96","type":"uint160"}]}],
+     "outputs":[
+        {"name":"amountOut","type":"uint256"},
+        {"name":"sqrtPriceX96After","type":"uint160"},
+        {"name":"initializedTicksCrossed","type":"uint32"},
+        {"name":"gasEstimate","type":"uint256"}]},
+    {"type":"function","name":"quoteExactOutputSingle","stateMutability":"nonpayable","inputs":[
+        {"name":"params","type":"tuple","components":[
+            {"name":"tokenIn","type":"address"},
+            {"name":"tokenOut","type":"address"},
+            {"name":"amount","type":"uint256"},
+            {"name":"fee","type":"uint24"},
+            {"name":"sqrtPriceLimitX96","type":"uint160"}]}],
+     "outputs":[
+        {"name":"amountIn","type":"uint256"},
+        {"name":"sqrtPriceX96After","type":"uint160"},
+        {"name":"initializedTicksCrossed","type":"uint32"},
+        {"name":"gasEstimate","type":"uint256"}]}
+]`
+
+var quoterABI = mustParseABI(quoterV2ABI)
+
+type quoteExactInputSingleParams struct {
+    TokenIn           common.Address
+    TokenOut          common.Address
+    AmountIn          *big.Int
+    Fee               *big.Int
+    SqrtPriceLimitX96 *big.Int
+}
+
+type quoteExactOutputSingleParams struct {
+    TokenIn           common.Address
+    TokenOut          common.Address
+    Amount            *big.Int
+    Fee               *big.Int
+    SqrtPriceLimitX96 *big.Int
+}
+
+// QuoteExactInputSingle returns the output and resulting sqrt price of
+// swapping amountIn of tokenIn, as quoted by a QuoterV2 contract
+func (c *Caller) QuoteExactInputSingle(ctx context.Context, quoter, tokenIn, tokenOut string, fee uint32, amountIn *big.Int) (*big.Int, *big.Int, error) {
+    client, err := c.dial(ctx)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    params := quoteExactInputSingleParams{
+        TokenIn:           common.HexToAddress(tokenIn),
+        TokenOut:          common.HexToAddress(tokenOut),
+        AmountIn:          amountIn,
+        Fee:               big.NewInt(int64(fee)),
+        SqrtPriceLimitX96: new(big.Int),
+    }
+    values, err := callContract(ctx, client, quoterABI, common.HexToAddress(quoter), "quoteExactInputSingle", params)
+    if err != nil {
+        return nil, nil, fmt.Errorf("failed to quote exact input: %v", err)
+    }
+
+    return values[0].(*big.Int), values[1].(*big.Int), nil
+}
+
+// QuoteExactOutputSingle returns the input and resulting sqrt price of
+// receiving amountOut of tokenOut, as quoted by a QuoterV2 contract
+func (c *Caller) QuoteExactOutputSingle(ctx context.Context, quoter, tokenIn, tokenOut string, fee uint32, amountOut *big.Int) (*big.Int, *big.Int, error) {
+    client, err := c.dial(ctx)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    params := quoteExactOutputSingleParams{
+        TokenIn:           common.HexToAddress(tokenIn),
+        TokenOut:          common.HexToAddress(tokenOut),
+        Amount:            amountOut,
+        Fee:               big.NewInt(int64(fee)),
+        SqrtPriceLimitX96: new(big.Int),
+    }
+    values, err := callContract(ctx, client, quoterABI, common.HexToAddress(quoter), "quoteExactOutputSingle", params)
+    if err != nil {
+        return nil, nil, fmt.Errorf("failed to quote exact output: %v", err)
+    }
+
+    return values[0].(*big.Int), values[1].(*big.Int), nil
+}
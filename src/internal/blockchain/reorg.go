@@ -0,0 +1,180 @@
+package blockchain
+
+import (
+    "context"
+    "fmt"
+    "log"
+
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/core/types"
+)
+
+// reorgTrackingDepth is the number of recent block hashes kept to detect reorgs
+const reorgTrackingDepth = 64
+
+// blockTracker remembers the hashes of the most recent canonical blocks
+type blockTracker struct {
+    hashes map[uint64]common.Hash
+    latest uint64
+}
+
+func newBlockTracker() *blockTracker {
+    return &blockTracker{
+        hashes: make(map[uint64]common.Hash),
+    }
+}
+
+func (t *blockTracker) get(number uint64) (common.Hash, bool) {
+    hash, ok := t.hashes[number]
+    return hash, ok
+}
+
+func (t *blockTracker) set(number uint64, hash common.Hash) {
+    t.hashes[number] = hash
+    if number > t.latest {
+        t.latest = number
+    }
+    if number >= reorgTrackingDepth {
+        delete(t.hashes, number-reorgTrackingDepth)
+    }
+}
+
+func (t *blockTracker) remove(number uint64) {
+    delete(t.hashes, number)
+    if number == t.latest && number > 0 {
+        t.latest = number - 1
+    }
+}
+
+// handleHeader records a new chain head and rolls back stored events when its
+// parent does not match the block we saw at that height
+func (el *EventListener) handleHeader(ctx context.Context, header *types.Header) error {
+    number := header.Number.Uint64()
+    hash := header.Hash()
+
+    if known, ok := el.blocks.get(number); ok && known == hash {
+        return nil
+    }
+
+    reorged := number <= el.blocks.latest
+    if parent, ok := el.blocks.get(number - 1); ok && number > 0 && parent != header.ParentHash {
+        reorged = true
+    }
+
+    if reorged {
+        ancestor, err := el.rollback(ctx, header)
+        if err != nil {
+            return fmt.Errorf("failed to roll back reorg at block %d: %v", number, err)
+        }
+
+        log.Printf("Chain reorganization detected at block %d, common ancestor %d", number, ancestor)
+        if err := el.rewind(ctx, ancestor); err != nil {
+            return err
+        }
+    }
+
+    el.blocks.set(number, hash)
+    el.head = number
+    el.confirm(ctx)
+    return nil
+}
+
+// rollback removes the events of every tracked block replaced by the new head
+// and returns the number of the last block shared by both chains
+func (el *EventListener) rollback(ctx context.Context, header *types.Header) (uint64, error) {
+    number := header.Number.Uint64()
+
+    // Tracked blocks at or above the new head's height belong to the old chain
+    for n := el.blocks.latest; n >= number && n > 0; n-- {
+        if hash, ok := el.blocks.get(n); ok {
+            if err := el.orphanBlock(ctx, n, hash); err != nil {
+                return 0, err
+            }
+            el.blocks.remove(n)
+        }
+    }
+
+    // Walk back along the new chain until it meets a block we already know
+    parentHash := header.ParentHash
+    n := number - 1
+    for n > 0 {
+        known, ok := el.blocks.get(n)
+        if !ok {
+            log.Printf("Reorg deeper than %d tracked blocks, rolling back to block %d", reorgTrackingDepth, n)
+            break
+        }
+        if known == parentHash {
+            break
+        }
+
+        if err := el.orphanBlock(ctx, n, known); err != nil {
+            return 0, err
+        }
+
+        parent, err := el.client.HeaderByHash(ctx, parentHash)
+        if err != nil {
+            return 0, fmt.Errorf("failed to get header %s: %v", parentHash.Hex(), err)
+        }
+        el.blocks.set(n, parentHash)
+        parentHash = parent.ParentHash
+        n--
+    }
+
+    return n, nil
+}
+
+// rewind moves every checkpoint past the ancestor back to it and reloads the
+// listener, whose backfill then replays the new canonical blocks
+func (el *EventListener) rewind(ctx context.Context, ancestor uint64) error {
+    for address, cursor := range el.cursors {
+        if cursor > ancestor {
+            if err := el.saveCheckpoint(ctx, address, ancestor); err != nil {
+                return err
+            }
+        }
+    }
+
+    el.Reload()
+    return nil
+}
+
+func (el *EventListener) orphanBlock(ctx context.Context, number uint64, hash common.Hash) error {
+    deleted, err := el.db.DeleteEventsByBlockHash(ctx, hash.Hex())
+    if err != nil {
+        return err
+    }
+
+    if deleted > 0 {
+        log.Printf("Removed %d events from orphaned block %d (%s)", deleted, number, hash.Hex())
+    }
+
+    // Pools the factory created in the orphaned block no longer exist
+    pools, err := el.db.DeletePoolsByBlockHash(ctx, hash.Hex())
+    if err != nil {
+        return err
+    }
+    if pools > 0 {
+        log.Printf("Removed %d pools created in orphaned block %d (%s)", pools, number, hash.Hex())
+        el.Reload()
+    }
+    return nil
+}
+
+// handleRemovedLog drops the events of a block the node reports as reverted
+// and rewinds the contract's checkpoint so the replacement logs are processed
+func (el *EventListener) handleRemovedLog(ctx context.Context, vLog types.Log) {
+    if known, ok := el.blocks.get(vLog.BlockNumber); ok && known == vLog.BlockHash {
+        el.blocks.remove(vLog.BlockNumber)
+    }
+
+    if err := el.orphanBlock(ctx, vLog.BlockNumber, vLog.BlockHash); err != nil {
+        log.Printf("Failed to remove events of reverted block: %v", err)
+        return
+    }
+
+    if cursor, ok := el.cursors[vLog.Address]; ok && vLog.BlockNumber > 0 && cursor >= vLog.BlockNumber {
+        if err := el.saveCheckpoint(ctx, vLog.Address, vLog.BlockNumber-1); err != nil {
+            log.Printf("Failed to save checkpoint: %v", err)
+        }
+    }
+}
@@ -0,0 +1,470 @@
+package blockchain
+
+import (
+    "context"
+    "crypto/ecdsa"
+    "math/big"
+    "testing"
+    "time"
+
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/common/hexutil"
+    "github.com/ethereum/go-ethereum/common/math"
+    "github.com/ethereum/go-ethereum/core/types"
+    "github.com/ethereum/go-ethereum/crypto"
+    "github.com/ethereum/go-ethereum/ethclient/simulated"
+
+    "src/internal/database"
+)
+
+// emitterCode is the runtime code of a contract that emits whatever log its
+// calldata describes: a word holding the topic count (3 or 4), the topics,
+// then the log data. It stands in for the tokens and pools on the simulated chain.
+var emitterCode = hexutil.MustDecode("0x366000600037600051600414602057606051604051602051608036036080a3005b60805160605160405160205160a0360360a0a400")
+
+var (
+    testToken = common.HexToAddress("0x1000000000000000000000000000000000000001")
+    testPool  = common.HexToAddress("0x2000000000000000000000000000000000000002")
+    testOther = common.HexToAddress("0x3000000000000000000000000000000000000003") // token1 of the pool, not indexed
+
+    alice = common.HexToAddress("0xa11ce00000000000000000000000000000000000")
+    bob   = common.HexToAddress("0xb0b0000000000000000000000000000000000000")
+    carol = common.HexToAddress("0xca20100000000000000000000000000000000000")
+)
+
+// testChain is a simulated chain the listener follows through its client
+type testChain struct {
+    t       *testing.T
+    backend *simulated.Backend
+    key     *ecdsa.PrivateKey
+    signer  types.Signer
+    nonce   uint64
+}
+
+// simClient adds the Close the listener expects to the simulated client
+type simClient struct {
+    simulated.Client
+}
+
+func (simClient) Close() {}
+
+func newTestChain(t *testing.T) *testChain {
+    key, err := crypto.GenerateKey()
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    backend := simulated.NewBackend(types.GenesisAlloc{
+        crypto.PubkeyToAddress(key.PublicKey): {Balance: new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))},
+        testToken: {Code: emitterCode},
+        testPool:  {Code: emitterCode},
+    })
+    t.Cleanup(func() { backend.Close() })
+
+    chainID, err := backend.Client().ChainID(context.Background())
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    return &testChain{
+        t:       t,
+        backend: backend,
+        key:     key,
+        signer:  types.LatestSignerForChainID(chainID),
+    }
+}
+
+// emit sends a transaction making contract emit a log. After a fork the same
+// nonces are reused at a higher gas price, replacing the transactions of the
+// orphaned blocks that the node puts back into its pool.
+func (c *testChain) emit(contract common.Address, topics []common.Hash, data []byte, gasPrice int64) {
+    calldata := common.LeftPadBytes(big.NewInt(int64(len(topics))).Bytes(), 32)
+    for _, topic := range topics {
+        calldata = append(calldata, topic.Bytes()...)
+    }
+    calldata = append(calldata, data...)
+
+    tx, err := types.SignTx(types.NewTx(&types.LegacyTx{
+        Nonce:    c.nonce,
+        GasPrice: big.NewInt(gasPrice),
+        Gas:      100000,
+        To:       &contract,
+        Data:     calldata,
+    }), c.signer, c.key)
+    if err != nil {
+        c.t.Fatal(err)
+    }
+
+    // The pool catches up with a fork asynchronously
+    deadline := time.Now().Add(5 * time.Second)
+    for {
+        err = c.backend.Client().SendTransaction(context.Background(), tx)
+        if err == nil || time.Now().After(deadline) {
+            break
+        }
+        time.Sleep(10 * time.Millisecond)
+    }
+    if err != nil {
+        c.t.Fatalf("failed to send transaction: %v", err)
+    }
+    c.nonce++
+}
+
+// fork makes the chain continue from parent, the way a reorg replaces blocks
+func (c *testChain) fork(parent common.Hash) {
+    if err := c.backend.Fork(parent); err != nil {
+        c.t.Fatal(err)
+    }
+    header, err := c.backend.Client().HeaderByHash(context.Background(), parent)
+    if err != nil {
+        c.t.Fatal(err)
+    }
+    nonce, err := c.backend.Client().NonceAt(context.Background(), crypto.PubkeyToAddress(c.key.PublicKey), header.Number)
+    if err != nil {
+        c.t.Fatal(err)
+    }
+    c.nonce = nonce
+}
+
+func (c *testChain) transfer(from, to common.Address, amount int64, gasPrice int64) {
+    c.emit(testToken, []common.Hash{transferTopic, addressTopic(from), addressTopic(to)},
+        common.LeftPadBytes(big.NewInt(amount).Bytes(), 32), gasPrice)
+}
+
+func (c *testChain) mint(owner common.Address, tickLower, tickUpper int64, amount0, amount1 int64, gasPrice int64) {
+    data, err := poolABI.Events["Mint"].Inputs.NonIndexed().Pack(owner, big.NewInt(1000), big.NewInt(amount0), big.NewInt(amount1))
+    if err != nil {
+        c.t.Fatal(err)
+    }
+    c.emit(testPool, []common.Hash{PoolEventTopics[0], addressTopic(owner), intTopic(tickLower), intTopic(tickUpper)}, data, gasPrice)
+}
+
+func (c *testChain) swap(amount0, amount1 int64, sqrtPriceX96 *big.Int, tick int64, gasPrice int64) {
+    data, err := poolABI.Events["Swap"].Inputs.NonIndexed().Pack(big.NewInt(amount0), big.NewInt(amount1), sqrtPriceX96, big.NewInt(1000), big.NewInt(tick))
+    if err != nil {
+        c.t.Fatal(err)
+    }
+    c.emit(testPool, []common.Hash{PoolEventTopics[2], addressTopic(alice), addressTopic(alice)}, data, gasPrice)
+}
+
+func addressTopic(address common.Address) common.Hash {
+    return common.BytesToHash(address.Bytes())
+}
+
+func intTopic(v int64) common.Hash {
+    return common.BytesToHash(math.U256Bytes(big.NewInt(v)))
+}
+
+// newTestListener registers the test token and pool and returns a listener
+// following chain that has not been started
+func newTestListener(t *testing.T, chain *testChain, db database.Service) *EventListener {
+    ctx := context.Background()
+    for _, contract := range []*database.Contract{
+        {Address: testToken.Hex(), Kind: database.ContractKindERC20, Name: "Token"},
+        {Address: testPool.Hex(), Kind: database.ContractKindV3Pool, Token0Address: testToken.Hex(), Token1Address: testOther.Hex()},
+    } {
+        if err := db.SeedContract(ctx, contract); err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    return &EventListener{
+        watched:    make(map[common.Address]*database.Contract),
+        cursors:    make(map[common.Address]uint64),
+        blocks:     newBlockTracker(),
+        blockTimes: make(map[common.Hash]time.Time),
+        reload:     make(chan struct{}, 1),
+        dial: func(ctx context.Context, url string) (chainClient, error) {
+            return simClient{chain.backend.Client()}, nil
+        },
+        blockRange: 100,
+        db:         db,
+    }
+}
+
+// startListener runs a listener over chain and db until the test ends
+func startListener(t *testing.T, chain *testChain, db database.Service) *EventListener {
+    el := newTestListener(t, chain, db)
+
+    ctx, cancel := context.WithCancel(context.Background())
+    done := make(chan struct{})
+    go func() {
+        el.Start(ctx)
+        close(done)
+    }()
+    t.Cleanup(func() {
+        cancel()
+        <-done
+    })
+
+    eventually(t, "listener to connect", func() bool {
+        return el.Health()["status"] == StateConnected
+    })
+    return el
+}
+
+// eventually polls cond until it holds, failing the test after a few seconds
+func eventually(t *testing.T, what string, cond func() bool) {
+    t.Helper()
+    deadline := time.Now().Add(10 * time.Second)
+    for !cond() {
+        if time.Now().After(deadline) {
+            t.Fatalf("timed out waiting for %s", what)
+        }
+        time.Sleep(20 * time.Millisecond)
+    }
+}
+
+// indexed reports whether every stored token and pool event of the test
+// contracts comes from one of blocks, and each of those blocks has events
+func indexed(db database.Service, blocks ...common.Hash) bool {
+    ctx := context.Background()
+    transfers, err := db.GetTransactionsByToken(ctx, testToken.Hex())
+    if err != nil {
+        return false
+    }
+    poolEvents, err := db.GetPoolTransactions(ctx, testPool.Hex(), false)
+    if err != nil {
+        return false
+    }
+
+    seen := make(map[string]bool)
+    for _, tx := range transfers {
+        seen[tx.BlockHash] = true
+    }
+    for _, tx := range poolEvents {
+        seen[tx.BlockHash] = true
+    }
+    if len(seen) != len(blocks) {
+        return false
+    }
+    for _, hash := range blocks {
+        if !seen[hash.Hex()] {
+            return false
+        }
+    }
+    return true
+}
+
+// onMongo reports whether db is MongoDB rather than memStore
+func onMongo(db database.Service) bool {
+    _, ok := db.(*memStore)
+    return !ok
+}
+
+func balanceOf(t *testing.T, db database.Service, holder common.Address) string {
+    balances, err := db.GetBalances(context.Background(), holder.Hex())
+    if err != nil {
+        t.Fatal(err)
+    }
+    for _, balance := range balances {
+        if balance.TokenAddress == testToken.Hex() {
+            return balance.Balance
+        }
+    }
+    return "0"
+}
+
+// Prices of the test swaps; priceOrphaned is only reached on the branch a reorg replaces
+var (
+    priceStart    = new(big.Int).Lsh(big.NewInt(1), 96)
+    priceOrphaned = new(big.Int).Lsh(big.NewInt(2), 96)
+    priceReplaced = new(big.Int).Div(new(big.Int).Lsh(big.NewInt(9), 96), big.NewInt(10))
+)
+
+// buildBlocks commits block 1, where alice gets tokens, provides liquidity and
+// swaps, and block 2, where she pays bob and the price doubles
+func buildBlocks(chain *testChain) (block1, block2 common.Hash) {
+    chain.transfer(common.Address{}, alice, 1000, 1e9)
+    chain.mint(alice, -600, 600, 1000, 2000, 1e9)
+    chain.swap(100, -50, priceStart, 0, 1e9)
+    block1 = chain.backend.Commit()
+
+    chain.transfer(alice, bob, 300, 1e9)
+    chain.swap(10, -5, priceOrphaned, 13863, 1e9)
+    block2 = chain.backend.Commit()
+    return block1, block2
+}
+
+// reorgBlocks replaces block 2 with a branch where alice pays carol and the
+// price drops, and returns the replacement block
+func reorgBlocks(chain *testChain, block1 common.Hash) common.Hash {
+    chain.fork(block1)
+    chain.transfer(alice, carol, 200, 2e9)
+    chain.swap(-20, 40, priceReplaced, -1054, 2e9)
+    block2 := chain.backend.Commit()
+    chain.backend.Commit()
+    return block2
+}
+
+// checkReorged asserts that db holds the events of block 1 and the
+// replacement block and, on MongoDB, the balances, reserves and candles they
+// add up to
+func checkReorged(t *testing.T, db database.Service, orphaned common.Hash) {
+    t.Helper()
+    ctx := context.Background()
+
+    // Orphaned rows are gone, the replacement rows are stored
+    transfers, err := db.GetTransactionsByToken(ctx, testToken.Hex())
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(transfers) != 2 {
+        t.Errorf("got %d token events, want the mint and the transfer to carol", len(transfers))
+    }
+    poolEvents, err := db.GetPoolTransactions(ctx, testPool.Hex(), false)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(poolEvents) != 3 {
+        t.Errorf("got %d pool events, want 3", len(poolEvents))
+    }
+    for _, tx := range transfers {
+        if tx.BlockHash == orphaned.Hex() {
+            t.Errorf("token event %s/%d of the orphaned block is still stored", tx.TxHash, tx.LogIndex)
+        }
+    }
+    for _, tx := range poolEvents {
+        if tx.BlockHash == orphaned.Hex() {
+            t.Errorf("pool event %s/%d of the orphaned block is still stored", tx.TxHash, tx.LogIndex)
+        }
+    }
+
+    // memStore keeps no balances, reserves or candles
+    if !onMongo(db) {
+        return
+    }
+
+    // Balances are reverted
+    for holder, want := range map[common.Address]string{alice: "800", bob: "0", carol: "200"} {
+        if got := balanceOf(t, db, holder); got != want {
+            t.Errorf("balance of %s = %s, want %s", holder.Hex(), got, want)
+        }
+    }
+
+    // Reserves are reverted: the mint, the first swap and the replacement swap
+    reserves, err := db.GetPoolReserves(ctx, testPool.Hex())
+    if err != nil {
+        t.Fatal(err)
+    }
+    if reserves == nil || reserves.Reserve0 != "1080" || reserves.Reserve1 != "1990" {
+        t.Errorf("reserves = %+v, want 1080/1990", reserves)
+    }
+
+    // Candles are rebuilt without the orphaned swap
+    for interval := range database.CandleIntervals {
+        candles, err := db.GetCandles(ctx, testPool.Hex(), interval, time.Unix(0, 0), time.Now().Add(48*time.Hour))
+        if err != nil {
+            t.Fatal(err)
+        }
+        if len(candles) == 0 {
+            t.Errorf("no %s candles", interval)
+            continue
+        }
+
+        var swaps int64
+        for _, candle := range candles {
+            swaps += candle.Swaps
+            for _, price := range []string{candle.Open, candle.High, candle.Low, candle.Close} {
+                if price == priceOrphaned.String() {
+                    t.Errorf("%s candle at %s still holds the orphaned price", interval, candle.OpenTime)
+                }
+            }
+        }
+        if swaps != 2 {
+            t.Errorf("%s candles count %d swaps, want 2", interval, swaps)
+        }
+        if last := candles[len(candles)-1]; last.Close != priceReplaced.String() {
+            t.Errorf("%s candle closes at %s, want %s", interval, last.Close, priceReplaced)
+        }
+    }
+}
+
+// The node reports the reorg through removed logs and new heads while the
+// listener is subscribed
+func TestReorgReplacesOrphanedEvents(t *testing.T) {
+    chain := newTestChain(t)
+    db := newTestStore(t)
+    startListener(t, chain, db)
+
+    block1, block2 := buildBlocks(chain)
+    eventually(t, "blocks 1 and 2 to be indexed", func() bool { return indexed(db, block1, block2) })
+    if onMongo(db) {
+        if got := balanceOf(t, db, bob); got != "300" {
+            t.Fatalf("bob's balance before the reorg = %s, want 300", got)
+        }
+    }
+
+    replacement := reorgBlocks(chain, block1)
+    eventually(t, "the reorg to be rolled back and replayed", func() bool { return indexed(db, block1, replacement) })
+    checkReorged(t, db, block2)
+}
+
+// A new head whose parent differs from the tracked block rolls the orphaned
+// block back, rewinds the checkpoints and leaves the replay to the backfill
+func TestReorgHeaderRewindsCheckpoints(t *testing.T) {
+    chain := newTestChain(t)
+    db := newTestStore(t)
+    el := newTestListener(t, chain, db)
+    ctx := context.Background()
+
+    client, _ := el.dial(ctx, "")
+    el.client = client
+    el.topics = append([]common.Hash{transferTopic}, PoolEventTopics...)
+    if _, err := el.loadContracts(ctx); err != nil {
+        t.Fatal(err)
+    }
+
+    block1, block2 := buildBlocks(chain)
+    if err := el.backfill(ctx); err != nil {
+        t.Fatal(err)
+    }
+    for _, hash := range []common.Hash{block1, block2} {
+        header, err := client.HeaderByHash(ctx, hash)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if err := el.handleHeader(ctx, header); err != nil {
+            t.Fatal(err)
+        }
+    }
+    if !indexed(db, block1, block2) {
+        t.Fatal("blocks 1 and 2 were not indexed")
+    }
+
+    replacement := reorgBlocks(chain, block1)
+    header, err := client.HeaderByHash(ctx, replacement)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if err := el.handleHeader(ctx, header); err != nil {
+        t.Fatal(err)
+    }
+
+    if !indexed(db, block1) {
+        t.Error("events of the orphaned block were not removed")
+    }
+    for _, address := range []common.Address{testToken, testPool} {
+        checkpoint, err := db.GetCheckpoint(ctx, address.Hex())
+        if err != nil {
+            t.Fatal(err)
+        }
+        if checkpoint == nil || checkpoint.LastBlock != 1 {
+            t.Errorf("checkpoint of %s = %+v, want block 1", address.Hex(), checkpoint)
+        }
+    }
+    select {
+    case <-el.reload:
+    default:
+        t.Fatal("no reload requested to replay the new branch")
+    }
+
+    // The reload starts over with a backfill from the checkpoints
+    if err := el.backfill(ctx); err != nil {
+        t.Fatal(err)
+    }
+    if !indexed(db, block1, replacement) {
+        t.Fatal("the new branch was not replayed")
+    }
+    checkReorged(t, db, block2)
+}
@@ -0,0 +1,224 @@
+package blockchain
+
+import (
+    "context"
+    "fmt"
+    "os"
+    "sort"
+    "sync"
+    "testing"
+    "time"
+
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+
+    "src/internal/config"
+    "src/internal/database"
+)
+
+// newTestStore returns the database the listener tests write to. With
+// MONGODB_TEST_URI set they run against a fresh MongoDB database, which is
+// dropped afterwards; otherwise against memStore.
+func newTestStore(t *testing.T) database.Service {
+    uri := os.Getenv("MONGODB_TEST_URI")
+    if uri == "" {
+        return newMemStore()
+    }
+
+    name := fmt.Sprintf("listener_test_%d", time.Now().UnixNano())
+    db := database.New(config.DatabaseConfig{URI: uri, Name: name})
+    t.Cleanup(func() {
+        ctx := context.Background()
+        if client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri)); err == nil {
+            client.Database(name).Drop(ctx)
+            client.Disconnect(ctx)
+        }
+        db.Close(ctx)
+    })
+    return db
+}
+
+// memStore is an in-memory database.Service holding just the rows the listener
+// writes. It keeps no balances, reserves or candles; their rollback is tested
+// against MongoDB in the database package and by itest. Methods the listener
+// does not use panic through the nil embedded interface.
+type memStore struct {
+    database.Service
+
+    mu           sync.Mutex
+    transactions map[string]*database.Transaction
+    poolEvents   map[string]*database.PoolTransaction
+    checkpoints  map[string]uint64
+    contracts    map[string]*database.Contract
+    pools        map[string]*database.Pool
+}
+
+func newMemStore() *memStore {
+    return &memStore{
+        transactions: make(map[string]*database.Transaction),
+        poolEvents:   make(map[string]*database.PoolTransaction),
+        checkpoints:  make(map[string]uint64),
+        contracts:    make(map[string]*database.Contract),
+        pools:        make(map[string]*database.Pool),
+    }
+}
+
+func memKey(chainID uint64, txHash string, logIndex uint) string {
+    return fmt.Sprintf("%d/%s/%d", chainID, txHash, logIndex)
+}
+
+func (s *memStore) SaveTransaction(ctx context.Context, tx *database.Transaction) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    stored := *tx
+    s.transactions[memKey(tx.ChainID, tx.TxHash, tx.LogIndex)] = &stored
+    return nil
+}
+
+func (s *memStore) GetTransactionsByToken(ctx context.Context, tokenAddress string) ([]*database.Transaction, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    var transactions []*database.Transaction
+    for _, tx := range s.transactions {
+        if tx.TokenAddress == tokenAddress {
+            stored := *tx
+            transactions = append(transactions, &stored)
+        }
+    }
+    return transactions, nil
+}
+
+func (s *memStore) SavePoolTransaction(ctx context.Context, tx *database.PoolTransaction) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    stored := *tx
+    s.poolEvents[memKey(tx.ChainID, tx.TxHash, tx.LogIndex)] = &stored
+    return nil
+}
+
+func (s *memStore) GetPoolTransactions(ctx context.Context, poolAddress string, confirmedOnly bool) ([]*database.PoolTransaction, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    var transactions []*database.PoolTransaction
+    for _, tx := range s.poolEvents {
+        if tx.PoolAddress == poolAddress && (!confirmedOnly || tx.Status != database.StatusPending) {
+            stored := *tx
+            transactions = append(transactions, &stored)
+        }
+    }
+    sort.Slice(transactions, func(i, j int) bool {
+        return before(transactions[i], transactions[j])
+    })
+    return transactions, nil
+}
+
+func (s *memStore) DeleteEventsByBlockHash(ctx context.Context, blockHash string) (int64, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    var deleted int64
+    for key, tx := range s.transactions {
+        if tx.BlockHash == blockHash {
+            delete(s.transactions, key)
+            deleted++
+        }
+    }
+    for key, tx := range s.poolEvents {
+        if tx.BlockHash == blockHash {
+            delete(s.poolEvents, key)
+            deleted++
+        }
+    }
+    return deleted, nil
+}
+
+func (s *memStore) DeletePoolsByBlockHash(ctx context.Context, blockHash string) (int64, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    var deleted int64
+    for address, pool := range s.pools {
+        if pool.BlockHash != blockHash {
+            continue
+        }
+        if contract, ok := s.contracts[address]; ok && contract.BlockHash == blockHash {
+            delete(s.contracts, address)
+        }
+        delete(s.checkpoints, address)
+        delete(s.pools, address)
+        deleted++
+    }
+    return deleted, nil
+}
+
+func (s *memStore) GetCheckpoint(ctx context.Context, contractAddress string) (*database.Checkpoint, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    block, ok := s.checkpoints[contractAddress]
+    if !ok {
+        return nil, nil
+    }
+    return &database.Checkpoint{ContractAddress: contractAddress, LastBlock: block}, nil
+}
+
+func (s *memStore) SaveCheckpoint(ctx context.Context, contractAddress string, lastBlock uint64) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    s.checkpoints[contractAddress] = lastBlock
+    return nil
+}
+
+func (s *memStore) SeedContract(ctx context.Context, contract *database.Contract) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    if _, ok := s.contracts[contract.Address]; !ok {
+        stored := *contract
+        stored.Active = true
+        s.contracts[contract.Address] = &stored
+    }
+    return nil
+}
+
+func (s *memStore) SaveContract(ctx context.Context, contract *database.Contract) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    stored := *contract
+    s.contracts[contract.Address] = &stored
+    return nil
+}
+
+func (s *memStore) GetContracts(ctx context.Context, activeOnly bool) ([]*database.Contract, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    var contracts []*database.Contract
+    for _, contract := range s.contracts {
+        if !activeOnly || contract.Active {
+            stored := *contract
+            contracts = append(contracts, &stored)
+        }
+    }
+    return contracts, nil
+}
+
+func (s *memStore) SavePool(ctx context.Context, pool *database.Pool) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    stored := *pool
+    s.pools[pool.Address] = &stored
+    return nil
+}
+
+// before orders pool events by their position in the chain
+func before(a, b *database.PoolTransaction) bool {
+    return a.BlockNumber < b.BlockNumber || (a.BlockNumber == b.BlockNumber && a.LogIndex < b.LogIndex)
+}
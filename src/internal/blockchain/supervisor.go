@@ -0,0 +1,124 @@
+package blockchain
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log"
+    "math/rand/v2"
+    "strconv"
+    "sync"
+    "time"
+)
+
+// Connection states reported by EventListener.Health
+const (
+    StateConnecting   = "connecting"
+    StateConnected    = "connected"
+    StateReconnecting = "reconnecting"
+    StateStopped      = "stopped"
+    StateFailed       = "failed"
+)
+
+const (
+    initialReconnectDelay = time.Second
+    maxReconnectDelay     = time.Minute
+)
+
+// errReload is returned by listen when the contract registry has changed
+var errReload = errors.New("contract registry changed")
+
+// connectionState tracks the listener's connection for the health endpoint
+type connectionState struct {
+    mu        sync.RWMutex
+    state     string
+    lastError string
+    attempts  int
+    since     time.Time
+}
+
+func (s *connectionState) set(state string, err error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    s.state = state
+    s.since = time.Now()
+    if err != nil {
+        s.lastError = err.Error()
+    }
+}
+
+// Start runs the listener until ctx is cancelled, redialling the node with
+// jittered exponential backoff whenever the connection or a subscription fails.
+// It only returns an error once maxReconnects consecutive attempts have failed.
+func (el *EventListener) Start(ctx context.Context) error {
+    el.conn.set(StateConnecting, nil)
+
+    for {
+        err := el.listen(ctx)
+        if ctx.Err() != nil {
+            el.conn.set(StateStopped, nil)
+            return nil
+        }
+        if errors.Is(err, errReload) {
+            log.Println("Contract registry changed, resubscribing")
+            continue
+        }
+
+        el.conn.mu.Lock()
+        el.conn.attempts++
+        attempts := el.conn.attempts
+        el.conn.mu.Unlock()
+
+        if el.maxReconnects > 0 && attempts > el.maxReconnects {
+            el.conn.set(StateFailed, err)
+            return fmt.Errorf("giving up after %d reconnect attempts: %v", el.maxReconnects, err)
+        }
+
+        delay := reconnectDelay(attempts)
+        el.conn.set(StateReconnecting, err)
+        log.Printf("Event listener disconnected: %v. Reconnecting in %s (attempt %d)", err, delay, attempts)
+
+        select {
+        case <-time.After(delay):
+        case <-ctx.Done():
+            el.conn.set(StateStopped, nil)
+            return nil
+        }
+    }
+}
+
+// connected marks the listener as healthy and resets the reconnect budget
+func (el *EventListener) connected() {
+    el.conn.mu.Lock()
+    el.conn.attempts = 0
+    el.conn.mu.Unlock()
+
+    el.conn.set(StateConnected, nil)
+}
+
+// Health reports the state of the node connection
+func (el *EventListener) Health() map[string]string {
+    el.conn.mu.RLock()
+    defer el.conn.mu.RUnlock()
+
+    health := map[string]string{
+        "status":             el.conn.state,
+        "since":              el.conn.since.Format(time.RFC3339),
+        "reconnect_attempts": strconv.Itoa(el.conn.attempts),
+    }
+    if el.conn.lastError != "" {
+        health["last_error"] = el.conn.lastError
+    }
+    return health
+}
+
+// reconnectDelay doubles the delay for every failed attempt up to
+// maxReconnectDelay and picks a random value in its upper half
+func reconnectDelay(attempt int) time.Duration {
+    delay := maxReconnectDelay
+    if attempt < 16 {
+        delay = min(initialReconnectDelay<<(attempt-1), maxReconnectDelay)
+    }
+    return delay/2 + rand.N(delay/2+1)
+}
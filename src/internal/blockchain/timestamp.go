@@ -0,0 +1,34 @@
+package blockchain
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "github.com/ethereum/go-ethereum/common"
+)
+
+// blockTimeCacheSize bounds the number of block timestamps kept in memory
+const blockTimeCacheSize = 1024
+
+// blockTime returns the timestamp of the block with the given hash, fetching
+// the header once per block
+func (el *EventListener) blockTime(ctx context.Context, hash common.Hash) (time.Time, error) {
+    if timestamp, ok := el.blockTimes[hash]; ok {
+        return timestamp, nil
+    }
+
+    header, err := el.client.HeaderByHash(ctx, hash)
+    if err != nil {
+        return time.Time{}, fmt.Errorf("failed to get block header %s: %v", hash.Hex(), err)
+    }
+
+    // Events are processed in block order, so older entries are rarely needed again
+    if len(el.blockTimes) >= blockTimeCacheSize {
+        el.blockTimes = make(map[common.Hash]time.Time)
+    }
+
+    timestamp := time.Unix(int64(header.Time), 0).UTC()
+    el.blockTimes[hash] = timestamp
+    return timestamp, nil
+}
@@ -0,0 +1,67 @@
+package blockchain
+
+import (
+    "context"
+    "fmt"
+    "math/big"
+    "time"
+
+    "github.com/ethereum/go-ethereum/common"
+
+    "src/internal/database"
+)
+
+const erc20ViewABI = `[
+    {"type":"function","name":"name","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"string"}]},
+    {"type":"function","name":"symbol","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"string"}]},
+    {"type":"function","name":"decimals","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint8"}]},
+    {"type":"function","name":"balanceOf","stateMutability":"view","inputs":[{"name":"account","type":"address"}],"outputs":[{"name":"","type":"uint256"}]}
+]`
+
+var erc20ABI = mustParseABI(erc20ViewABI)
+
+// TokenMetadata reads decimals(), symbol() and name() from an ERC-20 token.
+// decimals() is required; name and symbol are optional in the standard and
+// left empty when the call fails.
+func (c *Caller) TokenMetadata(ctx context.Context, tokenAddress string) (*database.TokenMetadata, error) {
+    client, err := c.dial(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    address := common.HexToAddress(tokenAddress)
+    values, err := callContract(ctx, client, erc20ABI, address, "decimals")
+    if err != nil {
+        return nil, fmt.Errorf("failed to get decimals of %s: %v", address.Hex(), err)
+    }
+
+    metadata := &database.TokenMetadata{
+        Address:   address.Hex(),
+        Decimals:  values[0].(uint8),
+        UpdatedAt: time.Now(),
+    }
+
+    if values, err := callContract(ctx, client, erc20ABI, address, "symbol"); err == nil {
+        metadata.Symbol = values[0].(string)
+    }
+    if values, err := callContract(ctx, client, erc20ABI, address, "name"); err == nil {
+        metadata.Name = values[0].(string)
+    }
+
+    return metadata, nil
+}
+
+// BalanceOf reads the token balance of holder at the latest block
+func (c *Caller) BalanceOf(ctx context.Context, tokenAddress, holder string) (*big.Int, error) {
+    client, err := c.dial(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    address := common.HexToAddress(tokenAddress)
+    values, err := callContract(ctx, client, erc20ABI, address, "balanceOf", common.HexToAddress(holder))
+    if err != nil {
+        return nil, fmt.Errorf("failed to get balance of %s in %s: %v", holder, address.Hex(), err)
+    }
+    return values[0].(*big.Int), nil
+}
@@ -0,0 +1,146 @@
+package blockchain
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "time"
+
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/core/types"
+
+    "src/internal/database"
+)
+
+// Administrative event signatures of the Token contract, including those
+// inherited from Pausable and Ownable
+var (
+    PausedTopic               = common.HexToHash("0x62e78cea01bee320cd4e420270b5ea74000d11b0c9f74754ebdbfc544b05a258")
+    UnpausedTopic             = common.HexToHash("0x5db9ee0a495bf2e6ff9c91a7834c1ba4fdd244a5e8aa4e537bd38aeae4b073aa")
+    BlacklistedTopic          = common.HexToHash("0xffa4e6181777692565cf28528fc88fd1516ea86b56da075235fa575af6a4b855")
+    UnBlacklistedTopic        = common.HexToHash("0x117e3210bb9aa7d9baff172026820255c6f6c30ba8999d1c2fd88e2848137c4e")
+    OwnershipTransferredTopic = common.HexToHash("0x8be0079c531659141344cd1fd0a4f28419497f9722a3daafe3b4186f6b6457e0")
+)
+
+// TokenAdminTopics are the administrative events indexed on ERC-20 tokens
+var TokenAdminTopics = []common.Hash{PausedTopic, UnpausedTopic, BlacklistedTopic, UnBlacklistedTopic, OwnershipTransferredTopic}
+
+const tokenAdminEventsABI = `[
+    {"type":"event","name":"Paused","anonymous":false,"inputs":[{"name":"account","type":"address","indexed":false}]},
+    {"type":"event","name":"Unpaused","anonymous":false,"inputs":[{"name":"account","type":"address","indexed":false}]}
+]`
+
+var tokenAdminABI = mustParseABI(tokenAdminEventsABI)
+
+type TokenAdminEvent struct {
+    TransactionHash common.Hash
+    BlockNumber     uint64
+    BlockHash       common.Hash
+    LogIndex        uint
+    EventType       string // "Paused", "Unpaused", "Blacklisted", "UnBlacklisted" or "OwnershipTransferred"
+    TokenAddress    string
+    Account         string // Pauser for Paused and Unpaused, the listed account for (Un)Blacklisted
+    PreviousOwner   string // OwnershipTransferred only
+    NewOwner        string
+}
+
+// ParseTokenAdminEvent decodes an administrative token log, returning nil for any other event
+func ParseTokenAdminEvent(log types.Log) (*TokenAdminEvent, error) {
+    if len(log.Topics) == 0 {
+        return nil, nil
+    }
+
+    event := &TokenAdminEvent{
+        TransactionHash: log.TxHash,
+        BlockNumber:     log.BlockNumber,
+        BlockHash:       log.BlockHash,
+        LogIndex:        log.Index,
+        TokenAddress:    log.Address.Hex(),
+    }
+
+    switch log.Topics[0] {
+    case PausedTopic, UnpausedTopic:
+        // Paused(address account), Unpaused(address account)
+        event.EventType = "Paused"
+        if log.Topics[0] == UnpausedTopic {
+            event.EventType = "Unpaused"
+        }
+        data := make(map[string]interface{})
+        if err := tokenAdminABI.UnpackIntoMap(data, event.EventType, log.Data); err != nil {
+            return nil, fmt.Errorf("failed to decode %s data: %v", event.EventType, err)
+        }
+        event.Account = data["account"].(common.Address).Hex()
+
+    case BlacklistedTopic, UnBlacklistedTopic:
+        // Blacklisted(address indexed account), UnBlacklisted(address indexed account)
+        event.EventType = "Blacklisted"
+        if log.Topics[0] == UnBlacklistedTopic {
+            event.EventType = "UnBlacklisted"
+        }
+        if len(log.Topics) != 2 {
+            return nil, fmt.Errorf("invalid %s log: expected 2 topics, got %d", event.EventType, len(log.Topics))
+        }
+        event.Account = common.HexToAddress(log.Topics[1].Hex()).Hex()
+
+    case OwnershipTransferredTopic:
+        // OwnershipTransferred(address indexed previousOwner, address indexed newOwner)
+        if len(log.Topics) != 3 {
+            return nil, fmt.Errorf("invalid OwnershipTransferred log: expected 3 topics, got %d", len(log.Topics))
+        }
+        event.EventType = "OwnershipTransferred"
+        event.PreviousOwner = common.HexToAddress(log.Topics[1].Hex()).Hex()
+        event.NewOwner = common.HexToAddress(log.Topics[2].Hex()).Hex()
+
+    default:
+        return nil, nil
+    }
+
+    return event, nil
+}
+
+// processTokenAdminEvent saves an administrative token event and reports
+// whether the log was one
+func (el *EventListener) processTokenAdminEvent(vLog types.Log) (bool, error) {
+    event, err := ParseTokenAdminEvent(vLog)
+    if err != nil {
+        log.Printf("Failed to parse token admin event: %v", err)
+        return true, nil
+    }
+    if event == nil {
+        return false, nil
+    }
+
+    ctx := context.Background()
+    timestamp, err := el.blockTime(ctx, event.BlockHash)
+    if err != nil {
+        return true, err
+    }
+
+    record := &database.TokenAdminEvent{
+        TokenAddress:  event.TokenAddress,
+        EventType:     event.EventType,
+        Account:       event.Account,
+        PreviousOwner: event.PreviousOwner,
+        NewOwner:      event.NewOwner,
+        ChainID:       el.chainID,
+        TxHash:        event.TransactionHash.Hex(),
+        LogIndex:      event.LogIndex,
+        BlockNumber:   event.BlockNumber,
+        BlockHash:     event.BlockHash.Hex(),
+        Timestamp:     timestamp,
+        IndexedAt:     time.Now(),
+        Status:        el.status(event.BlockNumber),
+    }
+
+    if err := el.db.SaveTokenAdminEvent(ctx, record); err != nil {
+        log.Printf("Failed to save token admin event: %v", err)
+        return true, nil
+    }
+
+    if event.EventType == "OwnershipTransferred" {
+        log.Printf("Token %s ownership transferred from %s to %s at block %d", event.TokenAddress, event.PreviousOwner, event.NewOwner, event.BlockNumber)
+    } else {
+        log.Printf("Token %s %s %s at block %d", event.TokenAddress, event.EventType, event.Account, event.BlockNumber)
+    }
+    return true, nil
+}
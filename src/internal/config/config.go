@@ -1,13 +1,249 @@
 package config
 
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	_ "github.com/joho/godotenv/autoload"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultConfigFile is read when CONFIG_FILE is not set
+const DefaultConfigFile = "config.yaml"
+
+type Config struct {
+	Server     ServerConfig     `yaml:"server"`
+	Database   DatabaseConfig   `yaml:"database"`
+	Blockchain BlockchainConfig `yaml:"blockchain"`
+	Pricing    PricingConfig    `yaml:"pricing"`
+}
+
+type ServerConfig struct {
+	Port       int    `yaml:"port"`
+	AdminToken string `yaml:"admin_token"` // The /admin routes are only registered when set
+}
+
+type DatabaseConfig struct {
+	URI  string `yaml:"uri"`
+	Name string `yaml:"name"`
+}
+
 type BlockchainConfig struct {
-	NodeURL         string
-	ContractAddress string
+	NodeURL              string `yaml:"node_url"`
+	DeploymentsDir       string `yaml:"deployments_dir"`
+	ArtifactsDir         string `yaml:"artifacts_dir"`   // Hardhat compilation output, used to recognise proxy implementations
+	FactoryAddress       string `yaml:"factory_address"` // Optional UniswapV3Factory watched for new pools
+	BackfillBlockRange   uint64 `yaml:"backfill_block_range"`
+	ConfirmationDepth    uint64 `yaml:"confirmation_depth"`
+	ReconnectMaxAttempts int    `yaml:"reconnect_max_attempts"` // 0 retries forever
+}
+
+type PricingConfig struct {
+	QuoteToken    string   `yaml:"quote_token"`    // Optional token pool values are also expressed in, e.g. a stablecoin
+	VolumeWindows []string `yaml:"volume_windows"` // Durations such as 1h or 7d
+	QuoterAddress string   `yaml:"quoter_address"` // Optional QuoterV2 that swap quotes can be checked against
+}
+
+// Default returns the configuration used for a local Hardhat setup
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port: 8080,
+		},
+		Database: DatabaseConfig{
+			URI:  "mongodb://localhost:27017",
+			Name: "token_events",
+		},
+		Blockchain: BlockchainConfig{
+			NodeURL:              "ws://localhost:8545",
+			DeploymentsDir:       filepath.Join(projectDir(), "deployments", "hardhat"),
+			ArtifactsDir:         filepath.Join(projectDir(), "artifacts"),
+			BackfillBlockRange:   1000,
+			ConfirmationDepth:    0,
+			ReconnectMaxAttempts: 10,
+		},
+		Pricing: PricingConfig{
+			VolumeWindows: []string{"1h", "24h", "7d", "30d"},
+		},
+	}
+}
+
+// Load builds the configuration from the defaults, the YAML file named by
+// CONFIG_FILE (config.yaml if unset, optional unless CONFIG_FILE is set) and
+// environment variable overrides, and validates the result
+func Load() (*Config, error) {
+	cfg := Default()
+
+	path := os.Getenv("CONFIG_FILE")
+	required := path != ""
+	if !required {
+		path = DefaultConfigFile
+	}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %v", path, err)
+		}
+	case required || !errors.Is(err, os.ErrNotExist):
+		return nil, fmt.Errorf("failed to read config file %s: %v", path, err)
+	}
+
+	if err := cfg.applyEnv(); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// applyEnv overrides settings with the matching environment variables
+func (c *Config) applyEnv() error {
+	setString(&c.Server.AdminToken, "ADMIN_TOKEN")
+	setString(&c.Database.URI, "MONGODB_URI")
+	setString(&c.Database.Name, "MONGODB_DATABASE")
+	setString(&c.Blockchain.NodeURL, "NODE_URL")
+	setString(&c.Blockchain.DeploymentsDir, "DEPLOYMENTS_DIR")
+	setString(&c.Blockchain.ArtifactsDir, "ARTIFACTS_DIR")
+	setString(&c.Blockchain.FactoryAddress, "FACTORY_ADDRESS")
+	setString(&c.Pricing.QuoteToken, "QUOTE_TOKEN")
+	setString(&c.Pricing.QuoterAddress, "QUOTER_ADDRESS")
+	if value := os.Getenv("VOLUME_WINDOWS"); value != "" {
+		c.Pricing.VolumeWindows = strings.Split(value, ",")
+	}
+
+	if err := setInt(&c.Server.Port, "PORT"); err != nil {
+		return err
+	}
+	if err := setUint(&c.Blockchain.BackfillBlockRange, "BACKFILL_BLOCK_RANGE"); err != nil {
+		return err
+	}
+	if err := setUint(&c.Blockchain.ConfirmationDepth, "CONFIRMATION_DEPTH"); err != nil {
+		return err
+	}
+	return setInt(&c.Blockchain.ReconnectMaxAttempts, "RECONNECT_MAX_ATTEMPTS")
+}
+
+// Validate reports every invalid setting at once
+func (c *Config) Validate() error {
+	var problems []string
+
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		problems = append(problems, fmt.Sprintf("server.port must be between 1 and 65535, got %d", c.Server.Port))
+	}
+	if !strings.HasPrefix(c.Database.URI, "mongodb://") && !strings.HasPrefix(c.Database.URI, "mongodb+srv://") {
+		problems = append(problems, fmt.Sprintf("database.uri must be a mongodb:// or mongodb+srv:// URI, got %q", c.Database.URI))
+	}
+	if c.Database.Name == "" {
+		problems = append(problems, "database.name is required")
+	}
+	if !strings.HasPrefix(c.Blockchain.NodeURL, "ws://") && !strings.HasPrefix(c.Blockchain.NodeURL, "wss://") {
+		problems = append(problems, fmt.Sprintf("blockchain.node_url must be a ws:// or wss:// URL (subscriptions are required), got %q", c.Blockchain.NodeURL))
+	}
+	if info, err := os.Stat(c.Blockchain.DeploymentsDir); err != nil || !info.IsDir() {
+		problems = append(problems, fmt.Sprintf("blockchain.deployments_dir %q is not a directory", c.Blockchain.DeploymentsDir))
+	}
+	if c.Blockchain.FactoryAddress != "" && !common.IsHexAddress(c.Blockchain.FactoryAddress) {
+		problems = append(problems, fmt.Sprintf("blockchain.factory_address %q is not a valid address", c.Blockchain.FactoryAddress))
+	}
+	if c.Pricing.QuoteToken != "" && !common.IsHexAddress(c.Pricing.QuoteToken) {
+		problems = append(problems, fmt.Sprintf("pricing.quote_token %q is not a valid address", c.Pricing.QuoteToken))
+	}
+	if c.Pricing.QuoterAddress != "" && !common.IsHexAddress(c.Pricing.QuoterAddress) {
+		problems = append(problems, fmt.Sprintf("pricing.quoter_address %q is not a valid address", c.Pricing.QuoterAddress))
+	}
+	for _, window := range c.Pricing.VolumeWindows {
+		if _, err := ParseWindow(window); err != nil {
+			problems = append(problems, fmt.Sprintf("pricing.volume_windows: %v", err))
+		}
+	}
+	if c.Blockchain.BackfillBlockRange == 0 {
+		problems = append(problems, "blockchain.backfill_block_range must be greater than 0")
+	}
+	if c.Blockchain.ReconnectMaxAttempts < 0 {
+		problems = append(problems, "blockchain.reconnect_max_attempts must not be negative")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
+
+// ParseWindow parses a time window such as 30m, 24h or 7d. Days are accepted
+// in addition to the units of time.ParseDuration.
+func ParseWindow(window string) (time.Duration, error) {
+	window = strings.TrimSpace(window)
+	if days, ok := strings.CutSuffix(window, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid window %q", window)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	duration, err := time.ParseDuration(window)
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("invalid window %q", window)
+	}
+	return duration, nil
 }
 
-func GetBlockchainConfig() BlockchainConfig {
-	return BlockchainConfig{
-		NodeURL:         "http://localhost:8545",
-		ContractAddress: "YOUR_CONTRACT_ADDRESS",
+// projectDir is the Hardhat project root, the parent of the src directory
+func projectDir() string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "."
 	}
-}
\ No newline at end of file
+
+	// Navigate up one level if we're in the src directory
+	if filepath.Base(cwd) == "src" {
+		cwd = filepath.Dir(cwd)
+	}
+
+	return cwd
+}
+
+func setString(target *string, name string) {
+	if value := os.Getenv(name); value != "" {
+		*target = value
+	}
+}
+
+func setInt(target *int, name string) error {
+	value := os.Getenv(name)
+	if value == "" {
+		return nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s %q: %v", name, value, err)
+	}
+	*target = parsed
+	return nil
+}
+
+func setUint(target *uint64, name string) error {
+	value := os.Getenv(name)
+	if value == "" {
+		return nil
+	}
+
+	parsed, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid %s %q: %v", name, value, err)
+	}
+	*target = parsed
+	return nil
+}
@@ -0,0 +1,181 @@
+package database
+
+import (
+    "context"
+    "fmt"
+    "math/big"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func candleKey(poolAddress, interval string, openTime time.Time) bson.M {
+    return bson.M{"pool_address": poolAddress, "interval": interval, "open_time": openTime}
+}
+
+// before reports whether the log at (block, index) precedes the one at (otherBlock, otherIndex)
+func before(block uint64, index uint, otherBlock uint64, otherIndex uint) bool {
+    return block < otherBlock || (block == otherBlock && index < otherIndex)
+}
+
+// applySwapToCandles adds a swap to the pool's candle of every interval
+func (m *MongoDB) applySwapToCandles(ctx context.Context, tx *PoolTransaction) error {
+    if tx.EventType != "Swap" || tx.SqrtPriceX96 == "" {
+        return nil
+    }
+
+    for interval, size := range CandleIntervals {
+        filter := candleKey(tx.PoolAddress, interval, tx.Timestamp.UTC().Truncate(size))
+
+        var candle Candle
+        err := m.candleCollection.FindOne(ctx, filter).Decode(&candle)
+        switch {
+        case err == mongo.ErrNoDocuments:
+            candle = Candle{
+                PoolAddress:   tx.PoolAddress,
+                Interval:      interval,
+                OpenTime:      tx.Timestamp.UTC().Truncate(size),
+                Open:          tx.SqrtPriceX96,
+                High:          tx.SqrtPriceX96,
+                Low:           tx.SqrtPriceX96,
+                Close:         tx.SqrtPriceX96,
+                Volume0:       "0",
+                Volume1:       "0",
+                OpenBlock:     tx.BlockNumber,
+                OpenLogIndex:  tx.LogIndex,
+                CloseBlock:    tx.BlockNumber,
+                CloseLogIndex: tx.LogIndex,
+            }
+        case err != nil:
+            return fmt.Errorf("failed to get candle: %v", err)
+        }
+
+        addSwap(&candle, tx)
+        candle.UpdatedAt = time.Now()
+
+        _, err = m.candleCollection.ReplaceOne(ctx, filter, candle, options.Replace().SetUpsert(true))
+        if err != nil {
+            return fmt.Errorf("failed to save candle: %v", err)
+        }
+    }
+    return nil
+}
+
+// addSwap folds a swap into a candle; swaps may arrive out of order
+func addSwap(candle *Candle, tx *PoolTransaction) {
+    price, _ := new(big.Int).SetString(tx.SqrtPriceX96, 10)
+    high, _ := new(big.Int).SetString(candle.High, 10)
+    low, _ := new(big.Int).SetString(candle.Low, 10)
+
+    if price.Cmp(high) > 0 {
+        candle.High = tx.SqrtPriceX96
+    }
+    if price.Cmp(low) < 0 {
+        candle.Low = tx.SqrtPriceX96
+    }
+    if before(tx.BlockNumber, tx.LogIndex, candle.OpenBlock, candle.OpenLogIndex) {
+        candle.Open = tx.SqrtPriceX96
+        candle.OpenBlock, candle.OpenLogIndex = tx.BlockNumber, tx.LogIndex
+    }
+    if !before(tx.BlockNumber, tx.LogIndex, candle.CloseBlock, candle.CloseLogIndex) {
+        candle.Close = tx.SqrtPriceX96
+        candle.CloseBlock, candle.CloseLogIndex = tx.BlockNumber, tx.LogIndex
+    }
+
+    volume0, _ := new(big.Int).SetString(candle.Volume0, 10)
+    volume1, _ := new(big.Int).SetString(candle.Volume1, 10)
+    if amount0, ok := new(big.Int).SetString(tx.Amount0, 10); ok && amount0.Sign() > 0 {
+        volume0.Add(volume0, amount0)
+    }
+    if amount1, ok := new(big.Int).SetString(tx.Amount1, 10); ok && amount1.Sign() > 0 {
+        volume1.Add(volume1, amount1)
+    }
+    candle.Volume0 = volume0.String()
+    candle.Volume1 = volume1.String()
+    candle.Swaps++
+}
+
+// rebuildCandles recomputes the candles that contained the given swaps from
+// the swaps still stored, after those swaps were removed by a reorg. A high or
+// low cannot be taken back out of a candle incrementally.
+func (m *MongoDB) rebuildCandles(ctx context.Context, removed []*PoolTransaction) error {
+    type bucket struct {
+        pool     string
+        interval string
+        openTime time.Time
+    }
+
+    seen := make(map[bucket]bool)
+    for _, tx := range removed {
+        if tx.EventType != "Swap" {
+            continue
+        }
+        for interval, size := range CandleIntervals {
+            b := bucket{tx.PoolAddress, interval, tx.Timestamp.UTC().Truncate(size)}
+            if seen[b] {
+                continue
+            }
+            seen[b] = true
+
+            if _, err := m.candleCollection.DeleteOne(ctx, candleKey(b.pool, b.interval, b.openTime)); err != nil {
+                return fmt.Errorf("failed to delete candle: %v", err)
+            }
+
+            swaps, err := m.GetSwaps(ctx, b.pool, b.openTime, b.openTime.Add(size))
+            if err != nil {
+                return err
+            }
+            if len(swaps) == 0 {
+                continue
+            }
+
+            candle := Candle{
+                PoolAddress:   b.pool,
+                Interval:      b.interval,
+                OpenTime:      b.openTime,
+                Open:          swaps[0].SqrtPriceX96,
+                High:          swaps[0].SqrtPriceX96,
+                Low:           swaps[0].SqrtPriceX96,
+                Close:         swaps[0].SqrtPriceX96,
+                Volume0:       "0",
+                Volume1:       "0",
+                OpenBlock:     swaps[0].BlockNumber,
+                OpenLogIndex:  swaps[0].LogIndex,
+                CloseBlock:    swaps[0].BlockNumber,
+                CloseLogIndex: swaps[0].LogIndex,
+                UpdatedAt:     time.Now(),
+            }
+            for _, swap := range swaps {
+                addSwap(&candle, swap)
+            }
+            if _, err := m.candleCollection.InsertOne(ctx, candle); err != nil {
+                return fmt.Errorf("failed to save candle: %v", err)
+            }
+        }
+    }
+    return nil
+}
+
+// GetCandles returns the candles of a pool that open within [from, to), oldest first
+func (m *MongoDB) GetCandles(ctx context.Context, poolAddress, interval string, from, to time.Time) ([]*Candle, error) {
+    filter := bson.M{
+        "pool_address": poolAddress,
+        "interval":     interval,
+        "open_time":    bson.M{"$gte": from, "$lt": to},
+    }
+    opts := options.Find().SetSort(bson.D{{Key: "open_time", Value: 1}})
+
+    cursor, err := m.candleCollection.Find(ctx, filter, opts)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get candles: %v", err)
+    }
+    defer cursor.Close(ctx)
+
+    var candles []*Candle
+    if err = cursor.All(ctx, &candles); err != nil {
+        return nil, fmt.Errorf("failed to decode candles: %v", err)
+    }
+    return candles, nil
+}
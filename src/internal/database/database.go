@@ -4,11 +4,14 @@ import (
     "context"
     "fmt"
     "log"
+    "math/big"
     "time"
 
     "go.mongodb.org/mongo-driver/mongo"
     "go.mongodb.org/mongo-driver/mongo/options"
     "go.mongodb.org/mongo-driver/bson"
+
+    "src/internal/config"
 )
 
 type MongoDB struct {
@@ -16,13 +19,26 @@ type MongoDB struct {
     database   *mongo.Database
     collection *mongo.Collection
     poolCollection *mongo.Collection
+    checkpointCollection *mongo.Collection
+    contractCollection *mongo.Collection
+    poolsCollection *mongo.Collection
+    balanceCollection *mongo.Collection
+    tokenCollection *mongo.Collection
+    reserveCollection *mongo.Collection
+    candleCollection *mongo.Collection
+    proxyCollection *mongo.Collection
+    proxySwapCollection *mongo.Collection
+    adminCollection *mongo.Collection
 }
 
-func New() Service {
+// zeroAddress is the counterparty of mints and burns
+const zeroAddress = "0x0000000000000000000000000000000000000000"
+
+func New(cfg config.DatabaseConfig) Service {
     ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
     defer cancel()
 
-    client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017"))
+    client, err := mongo.Connect(ctx, options.Client().ApplyURI(cfg.URI))
     if err != nil {
         log.Fatalf("Failed to connect to MongoDB: %v", err)
     }
@@ -33,21 +49,44 @@ func New() Service {
         log.Fatalf("Failed to ping MongoDB: %v", err)
     }
 
-    database := client.Database("token_events")
+    database := client.Database(cfg.Name)
     collection := database.Collection("transactions")
     poolCollection := database.Collection("pool_transactions")
+    checkpointCollection := database.Collection("checkpoints")
+    contractCollection := database.Collection("contracts")
+    poolsCollection := database.Collection("pools")
+    balanceCollection := database.Collection("balances")
+    tokenCollection := database.Collection("tokens")
+    reserveCollection := database.Collection("pool_reserves")
+    candleCollection := database.Collection("candles")
+    proxyCollection := database.Collection("proxy_events")
+    proxySwapCollection := database.Collection("proxy_swaps")
+    adminCollection := database.Collection("token_admin_events")
 
     // Create indexes
     indexes := []mongo.IndexModel{
         {
             Keys: bson.D{{Key: "account_address", Value: 1}},
         },
+        {
+            Keys: bson.D{{Key: "from_address", Value: 1}},
+        },
+        {
+            Keys: bson.D{{Key: "to_address", Value: 1}},
+        },
         {
             Keys: bson.D{{Key: "token_address", Value: 1}},
         },
         {
             Keys: bson.D{{Key: "tx_hash", Value: 1}},
         },
+        {
+            Keys: bson.D{{Key: "block_hash", Value: 1}},
+        },
+        {
+            Keys: bson.D{{Key: "status", Value: 1}, {Key: "block_number", Value: 1}},
+        },
+        eventKeyIndex(),
     }
     poolIndexes := []mongo.IndexModel{
         {
@@ -59,6 +98,13 @@ func New() Service {
         {
             Keys: bson.D{{Key: "timestamp", Value: 1}},
         },
+        {
+            Keys: bson.D{{Key: "block_hash", Value: 1}},
+        },
+        {
+            Keys: bson.D{{Key: "status", Value: 1}, {Key: "block_number", Value: 1}},
+        },
+        eventKeyIndex(),
     }
 
     _, err = collection.Indexes().CreateMany(ctx, indexes)
@@ -71,11 +117,163 @@ func New() Service {
         log.Fatalf("Failed to create pool indexes: %v", err)
     }
 
+    _, err = checkpointCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+        Keys:    bson.D{{Key: "contract_address", Value: 1}},
+        Options: options.Index().SetUnique(true),
+    })
+    if err != nil {
+        log.Fatalf("Failed to create checkpoint indexes: %v", err)
+    }
+
+    _, err = contractCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+        Keys:    bson.D{{Key: "address", Value: 1}},
+        Options: options.Index().SetUnique(true),
+    })
+    if err != nil {
+        log.Fatalf("Failed to create contract indexes: %v", err)
+    }
+
+    _, err = poolsCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+        {
+            Keys:    bson.D{{Key: "address", Value: 1}},
+            Options: options.Index().SetUnique(true),
+        },
+        {
+            Keys: bson.D{{Key: "token0_address", Value: 1}, {Key: "token1_address", Value: 1}},
+        },
+        {
+            Keys: bson.D{{Key: "block_hash", Value: 1}},
+        },
+    })
+    if err != nil {
+        log.Fatalf("Failed to create pools indexes: %v", err)
+    }
+
+    _, err = balanceCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+        {
+            Keys:    bson.D{{Key: "token_address", Value: 1}, {Key: "holder", Value: 1}},
+            Options: options.Index().SetUnique(true),
+        },
+        {
+            Keys: bson.D{{Key: "holder", Value: 1}},
+        },
+    })
+    if err != nil {
+        log.Fatalf("Failed to create balance indexes: %v", err)
+    }
+
+    _, err = tokenCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+        Keys:    bson.D{{Key: "address", Value: 1}},
+        Options: options.Index().SetUnique(true),
+    })
+    if err != nil {
+        log.Fatalf("Failed to create token indexes: %v", err)
+    }
+
+    _, err = reserveCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+        Keys:    bson.D{{Key: "pool_address", Value: 1}},
+        Options: options.Index().SetUnique(true),
+    })
+    if err != nil {
+        log.Fatalf("Failed to create pool reserve indexes: %v", err)
+    }
+
+    _, err = candleCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+        Keys:    bson.D{{Key: "pool_address", Value: 1}, {Key: "interval", Value: 1}, {Key: "open_time", Value: 1}},
+        Options: options.Index().SetUnique(true),
+    })
+    if err != nil {
+        log.Fatalf("Failed to create candle indexes: %v", err)
+    }
+
+    _, err = proxyCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+        {
+            Keys: bson.D{{Key: "proxy_address", Value: 1}, {Key: "block_number", Value: 1}},
+        },
+        {
+            Keys: bson.D{{Key: "block_hash", Value: 1}},
+        },
+        {
+            Keys: bson.D{{Key: "status", Value: 1}, {Key: "block_number", Value: 1}},
+        },
+        eventKeyIndex(),
+    })
+    if err != nil {
+        log.Fatalf("Failed to create proxy event indexes: %v", err)
+    }
+
+    _, err = proxySwapCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+        {
+            Keys: bson.D{{Key: "user", Value: 1}, {Key: "block_number", Value: -1}},
+        },
+        {
+            Keys: bson.D{{Key: "block_hash", Value: 1}},
+        },
+        {
+            Keys: bson.D{{Key: "status", Value: 1}, {Key: "block_number", Value: 1}},
+        },
+        eventKeyIndex(),
+    })
+    if err != nil {
+        log.Fatalf("Failed to create proxy swap indexes: %v", err)
+    }
+
+    _, err = adminCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+        {
+            Keys: bson.D{{Key: "token_address", Value: 1}, {Key: "block_number", Value: 1}},
+        },
+        {
+            Keys: bson.D{{Key: "block_hash", Value: 1}},
+        },
+        {
+            Keys: bson.D{{Key: "status", Value: 1}, {Key: "block_number", Value: 1}},
+        },
+        eventKeyIndex(),
+    })
+    if err != nil {
+        log.Fatalf("Failed to create token admin event indexes: %v", err)
+    }
+
     return &MongoDB{
         client:     client,
         database:   database,
         collection: collection,
         poolCollection: poolCollection,
+        checkpointCollection: checkpointCollection,
+        contractCollection: contractCollection,
+        poolsCollection: poolsCollection,
+        balanceCollection: balanceCollection,
+        tokenCollection: tokenCollection,
+        reserveCollection: reserveCollection,
+        candleCollection: candleCollection,
+        proxyCollection: proxyCollection,
+        proxySwapCollection: proxySwapCollection,
+        adminCollection: adminCollection,
+    }
+}
+
+// eventKeyIndex makes a log unique per chain, skipping documents indexed
+// before log indexes were recorded
+func eventKeyIndex() mongo.IndexModel {
+    return mongo.IndexModel{
+        Keys: bson.D{
+            {Key: "chain_id", Value: 1},
+            {Key: "tx_hash", Value: 1},
+            {Key: "log_index", Value: 1},
+        },
+        Options: options.Index().
+            SetUnique(true).
+            SetPartialFilterExpression(bson.M{"log_index": bson.M{"$exists": true}}),
+    }
+}
+
+// eventKey identifies the log an event was decoded from. Event saves upsert on
+// it, so re-processing a log after a backfill overlap or reconnect is safe.
+func eventKey(chainID uint64, txHash string, logIndex uint) bson.M {
+    return bson.M{
+        "chain_id":  chainID,
+        "tx_hash":   txHash,
+        "log_index": logIndex,
     }
 }
 
@@ -100,16 +298,118 @@ func (m *MongoDB) Close(ctx context.Context) error {
     return m.client.Disconnect(ctx)
 }
 
+// SaveTransaction stores tx under its eventKey.
+// Holder balances are only updated the first time a transfer is stored.
 func (m *MongoDB) SaveTransaction(ctx context.Context, tx *Transaction) error {
-    _, err := m.collection.InsertOne(ctx, tx)
+    result, err := m.collection.ReplaceOne(ctx,
+        eventKey(tx.ChainID, tx.TxHash, tx.LogIndex),
+        tx,
+        options.Replace().SetUpsert(true),
+    )
     if err != nil {
         return fmt.Errorf("failed to save transaction: %v", err)
     }
+
+    if result.UpsertedCount > 0 {
+        return m.applyTransfer(ctx, tx, false)
+    }
+    return nil
+}
+
+// applyTransfer moves tx.Amount from the sender's balance to the recipient's,
+// or back again when revert is set
+func (m *MongoDB) applyTransfer(ctx context.Context, tx *Transaction, revert bool) error {
+    amount, ok := new(big.Int).SetString(tx.Amount, 10)
+    if !ok {
+        return fmt.Errorf("invalid transfer amount %q in %s", tx.Amount, tx.TxHash)
+    }
+
+    from, to := tx.FromAddress, tx.ToAddress
+    if from == "" && to == "" {
+        // Stored before transfers were tracked, only mints and burns exist
+        switch tx.EventType {
+        case "Mint":
+            from, to = zeroAddress, tx.AccountAddress
+        case "Burn":
+            from, to = tx.AccountAddress, zeroAddress
+        }
+    }
+
+    if revert {
+        from, to = to, from
+    }
+
+    if from != "" && from != zeroAddress {
+        if err := m.adjustBalance(ctx, tx.TokenAddress, from, new(big.Int).Neg(amount), tx.BlockNumber); err != nil {
+            return err
+        }
+    }
+    if to != "" && to != zeroAddress {
+        if err := m.adjustBalance(ctx, tx.TokenAddress, to, amount, tx.BlockNumber); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// adjustBalance adds delta to a holder's balance. Balances are uint256 values
+// stored as decimal strings, so they are updated with a read-modify-write; the
+// event listener is the only writer.
+func (m *MongoDB) adjustBalance(ctx context.Context, tokenAddress, holder string, delta *big.Int, blockNumber uint64) error {
+    filter := bson.M{"token_address": tokenAddress, "holder": holder}
+
+    balance := new(big.Int)
+    var current Balance
+    err := m.balanceCollection.FindOne(ctx, filter).Decode(&current)
+    switch {
+    case err == nil:
+        balance.SetString(current.Balance, 10)
+    case err != mongo.ErrNoDocuments:
+        return fmt.Errorf("failed to get balance: %v", err)
+    }
+
+    balance.Add(balance, delta)
+    _, err = m.balanceCollection.UpdateOne(ctx, filter,
+        bson.M{"$set": bson.M{
+            "balance":      balance.String(),
+            "block_number": max(blockNumber, current.BlockNumber),
+            "updated_at":   time.Now(),
+        }},
+        options.Update().SetUpsert(true),
+    )
+    if err != nil {
+        return fmt.Errorf("failed to update balance: %v", err)
+    }
     return nil
 }
 
-func (m *MongoDB) GetTransactionsByAccount(ctx context.Context, accountAddress string) ([]*Transaction, error) {
-    cursor, err := m.collection.Find(ctx, bson.M{"account_address": accountAddress})
+// GetBalances returns the running balances of a holder across all tokens
+func (m *MongoDB) GetBalances(ctx context.Context, holder string) ([]*Balance, error) {
+    cursor, err := m.balanceCollection.Find(ctx, bson.M{"holder": holder})
+    if err != nil {
+        return nil, fmt.Errorf("failed to get balances: %v", err)
+    }
+    defer cursor.Close(ctx)
+
+    var balances []*Balance
+    if err = cursor.All(ctx, &balances); err != nil {
+        return nil, fmt.Errorf("failed to decode balances: %v", err)
+    }
+
+    return balances, nil
+}
+
+func (m *MongoDB) GetTransactionsByAccount(ctx context.Context, accountAddress string, confirmedOnly bool) ([]*Transaction, error) {
+    filter := bson.M{"$or": bson.A{
+        bson.M{"account_address": accountAddress},
+        bson.M{"from_address": accountAddress},
+        bson.M{"to_address": accountAddress},
+    }}
+    if confirmedOnly {
+        filter["status"] = bson.M{"$ne": StatusPending}
+    }
+
+    cursor, err := m.collection.Find(ctx, filter)
     if err != nil {
         return nil, fmt.Errorf("failed to get transactions: %v", err)
     }
@@ -137,17 +437,116 @@ func (m *MongoDB) GetTransactionsByToken(ctx context.Context, tokenAddress strin
 
     return transactions, nil
 }
-// Add new methods to MongoDB struct
+
+// SavePoolTransaction stores tx under its eventKey.
+// Pool reserves and candles are only updated the first time an event is stored.
 func (m *MongoDB) SavePoolTransaction(ctx context.Context, tx *PoolTransaction) error {
-    _, err := m.poolCollection.InsertOne(ctx, tx)
+    result, err := m.poolCollection.ReplaceOne(ctx,
+        eventKey(tx.ChainID, tx.TxHash, tx.LogIndex),
+        tx,
+        options.Replace().SetUpsert(true),
+    )
     if err != nil {
         return fmt.Errorf("failed to save pool transaction: %v", err)
     }
+
+    if result.UpsertedCount > 0 {
+        if err := m.applyPoolEvent(ctx, tx, false); err != nil {
+            return err
+        }
+        return m.applySwapToCandles(ctx, tx)
+    }
+    return nil
+}
+
+// ReserveDeltas returns how much of token0 and token1 a pool event moved into
+// (positive) or out of (negative) the pool. Burns only credit the position, the
+// tokens leave the pool when they are collected.
+func ReserveDeltas(tx *PoolTransaction) (*big.Int, *big.Int) {
+    delta0, delta1 := new(big.Int), new(big.Int)
+    switch tx.EventType {
+    case "Mint", "Swap":
+        delta0.SetString(tx.Amount0, 10)
+        delta1.SetString(tx.Amount1, 10)
+    case "Collect":
+        delta0.SetString(tx.Amount0, 10)
+        delta1.SetString(tx.Amount1, 10)
+        delta0.Neg(delta0)
+        delta1.Neg(delta1)
+    case "Flash":
+        // The borrowed amounts are returned within the transaction, only the fees stay
+        delta0.SetString(tx.Paid0, 10)
+        delta1.SetString(tx.Paid1, 10)
+    }
+    return delta0, delta1
+}
+
+// applyPoolEvent adds the reserve changes of a pool event, or removes them
+// again when revert is set
+func (m *MongoDB) applyPoolEvent(ctx context.Context, tx *PoolTransaction, revert bool) error {
+    delta0, delta1 := ReserveDeltas(tx)
+    if delta0.Sign() == 0 && delta1.Sign() == 0 {
+        return nil
+    }
+    if revert {
+        delta0.Neg(delta0)
+        delta1.Neg(delta1)
+    }
+
+    filter := bson.M{"pool_address": tx.PoolAddress}
+
+    reserve0, reserve1 := new(big.Int), new(big.Int)
+    var current PoolReserves
+    err := m.reserveCollection.FindOne(ctx, filter).Decode(&current)
+    switch {
+    case err == nil:
+        reserve0.SetString(current.Reserve0, 10)
+        reserve1.SetString(current.Reserve1, 10)
+    case err != mongo.ErrNoDocuments:
+        return fmt.Errorf("failed to get pool reserves: %v", err)
+    }
+
+    reserve0.Add(reserve0, delta0)
+    reserve1.Add(reserve1, delta1)
+    _, err = m.reserveCollection.UpdateOne(ctx, filter,
+        bson.M{"$set": bson.M{
+            "token0_address": tx.Token0Address,
+            "token1_address": tx.Token1Address,
+            "reserve0":       reserve0.String(),
+            "reserve1":       reserve1.String(),
+            "block_number":   max(tx.BlockNumber, current.BlockNumber),
+            "updated_at":     time.Now(),
+        }},
+        options.Update().SetUpsert(true),
+    )
+    if err != nil {
+        return fmt.Errorf("failed to update pool reserves: %v", err)
+    }
     return nil
 }
 
-func (m *MongoDB) GetPoolTransactions(ctx context.Context, poolAddress string) ([]*PoolTransaction, error) {
-    cursor, err := m.poolCollection.Find(ctx, bson.M{"pool_address": poolAddress})
+// GetPoolReserves returns the running reserves of a pool, or nil if none of its
+// events have been indexed yet
+func (m *MongoDB) GetPoolReserves(ctx context.Context, poolAddress string) (*PoolReserves, error) {
+    var reserves PoolReserves
+    err := m.reserveCollection.FindOne(ctx, bson.M{"pool_address": poolAddress}).Decode(&reserves)
+    if err == mongo.ErrNoDocuments {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to get pool reserves: %v", err)
+    }
+    return &reserves, nil
+}
+
+func (m *MongoDB) GetPoolTransactions(ctx context.Context, poolAddress string, confirmedOnly bool) ([]*PoolTransaction, error) {
+    filter := bson.M{"pool_address": poolAddress}
+    if confirmedOnly {
+        filter["status"] = bson.M{"$ne": StatusPending}
+    }
+
+    opts := options.Find().SetSort(bson.D{{Key: "block_number", Value: 1}, {Key: "log_index", Value: 1}})
+    cursor, err := m.poolCollection.Find(ctx, filter, opts)
     if err != nil {
         return nil, fmt.Errorf("failed to get pool transactions: %v", err)
     }
@@ -159,4 +558,504 @@ func (m *MongoDB) GetPoolTransactions(ctx context.Context, poolAddress string) (
     }
 
     return transactions, nil
+}
+
+// GetLatestSwap returns the most recent Swap event of a pool, or nil if none has been indexed
+func (m *MongoDB) GetLatestSwap(ctx context.Context, poolAddress string) (*PoolTransaction, error) {
+    opts := options.FindOne().SetSort(bson.D{{Key: "block_number", Value: -1}, {Key: "log_index", Value: -1}})
+
+    var swap PoolTransaction
+    err := m.poolCollection.FindOne(ctx, bson.M{"pool_address": poolAddress, "event_type": "Swap"}, opts).Decode(&swap)
+    if err == mongo.ErrNoDocuments {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to get latest swap: %v", err)
+    }
+    return &swap, nil
+}
+
+// GetSwaps returns the swaps of a pool with a block time in [from, to), in log order
+func (m *MongoDB) GetSwaps(ctx context.Context, poolAddress string, from, to time.Time) ([]*PoolTransaction, error) {
+    filter := bson.M{
+        "pool_address": poolAddress,
+        "event_type":   "Swap",
+        "timestamp":    bson.M{"$gte": from, "$lt": to},
+    }
+    opts := options.Find().SetSort(bson.D{{Key: "block_number", Value: 1}, {Key: "log_index", Value: 1}})
+
+    cursor, err := m.poolCollection.Find(ctx, filter, opts)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get swaps: %v", err)
+    }
+    defer cursor.Close(ctx)
+
+    var swaps []*PoolTransaction
+    if err = cursor.All(ctx, &swaps); err != nil {
+        return nil, fmt.Errorf("failed to decode swaps: %v", err)
+    }
+    return swaps, nil
+}
+
+// GetPositionEvents returns the Mint and Burn events of a pool in log order
+func (m *MongoDB) GetPositionEvents(ctx context.Context, poolAddress string) ([]*PoolTransaction, error) {
+    filter := bson.M{
+        "pool_address": poolAddress,
+        "event_type":   bson.M{"$in": bson.A{"Mint", "Burn"}},
+    }
+    opts := options.Find().SetSort(bson.D{{Key: "block_number", Value: 1}, {Key: "log_index", Value: 1}})
+
+    cursor, err := m.poolCollection.Find(ctx, filter, opts)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get position events: %v", err)
+    }
+    defer cursor.Close(ctx)
+
+    var events []*PoolTransaction
+    if err = cursor.All(ctx, &events); err != nil {
+        return nil, fmt.Errorf("failed to decode position events: %v", err)
+    }
+    return events, nil
+}
+
+// GetSwapBefore returns the last swap of a pool with a block time at or before t,
+// or nil if there is none
+func (m *MongoDB) GetSwapBefore(ctx context.Context, poolAddress string, t time.Time) (*PoolTransaction, error) {
+    filter := bson.M{
+        "pool_address": poolAddress,
+        "event_type":   "Swap",
+        "timestamp":    bson.M{"$lte": t},
+    }
+    opts := options.FindOne().SetSort(bson.D{{Key: "block_number", Value: -1}, {Key: "log_index", Value: -1}})
+
+    var swap PoolTransaction
+    err := m.poolCollection.FindOne(ctx, filter, opts).Decode(&swap)
+    if err == mongo.ErrNoDocuments {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to get swap: %v", err)
+    }
+    return &swap, nil
+}
+
+// ConfirmEvents promotes every pending event at or below upToBlock to confirmed
+func (m *MongoDB) ConfirmEvents(ctx context.Context, upToBlock uint64) (int64, error) {
+    filter := bson.M{
+        "status":       StatusPending,
+        "block_number": bson.M{"$lte": upToBlock},
+    }
+    update := bson.M{"$set": bson.M{"status": StatusConfirmed}}
+
+    result, err := m.collection.UpdateMany(ctx, filter, update)
+    if err != nil {
+        return 0, fmt.Errorf("failed to confirm transactions: %v", err)
+    }
+
+    poolResult, err := m.poolCollection.UpdateMany(ctx, filter, update)
+    if err != nil {
+        return 0, fmt.Errorf("failed to confirm pool transactions: %v", err)
+    }
+
+    proxyResult, err := m.proxyCollection.UpdateMany(ctx, filter, update)
+    if err != nil {
+        return 0, fmt.Errorf("failed to confirm proxy events: %v", err)
+    }
+
+    swapResult, err := m.proxySwapCollection.UpdateMany(ctx, filter, update)
+    if err != nil {
+        return 0, fmt.Errorf("failed to confirm proxy swaps: %v", err)
+    }
+
+    adminResult, err := m.adminCollection.UpdateMany(ctx, filter, update)
+    if err != nil {
+        return 0, fmt.Errorf("failed to confirm token admin events: %v", err)
+    }
+
+    return result.ModifiedCount + poolResult.ModifiedCount + proxyResult.ModifiedCount + swapResult.ModifiedCount + adminResult.ModifiedCount, nil
+}
+
+// DeleteEventsByBlockHash removes every token, token admin, pool and proxy event and proxy swap recorded in a block
+// that is no longer part of the canonical chain
+func (m *MongoDB) DeleteEventsByBlockHash(ctx context.Context, blockHash string) (int64, error) {
+    filter := bson.M{"block_hash": blockHash}
+
+    // Undo the balance and reserve changes of the orphaned events first
+    cursor, err := m.collection.Find(ctx, filter)
+    if err != nil {
+        return 0, fmt.Errorf("failed to get orphaned transactions: %v", err)
+    }
+    var orphaned []*Transaction
+    if err = cursor.All(ctx, &orphaned); err != nil {
+        return 0, fmt.Errorf("failed to decode orphaned transactions: %v", err)
+    }
+    for _, tx := range orphaned {
+        if err := m.applyTransfer(ctx, tx, true); err != nil {
+            return 0, err
+        }
+    }
+
+    poolCursor, err := m.poolCollection.Find(ctx, filter)
+    if err != nil {
+        return 0, fmt.Errorf("failed to get orphaned pool transactions: %v", err)
+    }
+    var orphanedPool []*PoolTransaction
+    if err = poolCursor.All(ctx, &orphanedPool); err != nil {
+        return 0, fmt.Errorf("failed to decode orphaned pool transactions: %v", err)
+    }
+    for _, tx := range orphanedPool {
+        if err := m.applyPoolEvent(ctx, tx, true); err != nil {
+            return 0, err
+        }
+    }
+
+    result, err := m.collection.DeleteMany(ctx, filter)
+    if err != nil {
+        return 0, fmt.Errorf("failed to delete transactions: %v", err)
+    }
+
+    poolResult, err := m.poolCollection.DeleteMany(ctx, filter)
+    if err != nil {
+        return 0, fmt.Errorf("failed to delete pool transactions: %v", err)
+    }
+
+    if err := m.rebuildCandles(ctx, orphanedPool); err != nil {
+        return 0, err
+    }
+
+    proxyResult, err := m.proxyCollection.DeleteMany(ctx, filter)
+    if err != nil {
+        return 0, fmt.Errorf("failed to delete proxy events: %v", err)
+    }
+
+    swapResult, err := m.proxySwapCollection.DeleteMany(ctx, filter)
+    if err != nil {
+        return 0, fmt.Errorf("failed to delete proxy swaps: %v", err)
+    }
+
+    adminResult, err := m.adminCollection.DeleteMany(ctx, filter)
+    if err != nil {
+        return 0, fmt.Errorf("failed to delete token admin events: %v", err)
+    }
+
+    return result.DeletedCount + poolResult.DeletedCount + proxyResult.DeletedCount + swapResult.DeletedCount + adminResult.DeletedCount, nil
+}
+
+// SaveProxyEvent stores event under its eventKey
+func (m *MongoDB) SaveProxyEvent(ctx context.Context, event *ProxyEvent) error {
+    _, err := m.proxyCollection.ReplaceOne(ctx,
+        eventKey(event.ChainID, event.TxHash, event.LogIndex),
+        event,
+        options.Replace().SetUpsert(true),
+    )
+    if err != nil {
+        return fmt.Errorf("failed to save proxy event: %v", err)
+    }
+    return nil
+}
+
+// GetProxyEvents returns the upgrade and admin history of a proxy, oldest first
+func (m *MongoDB) GetProxyEvents(ctx context.Context, proxyAddress string) ([]*ProxyEvent, error) {
+    opts := options.Find().SetSort(bson.D{{Key: "block_number", Value: 1}, {Key: "log_index", Value: 1}})
+    cursor, err := m.proxyCollection.Find(ctx, bson.M{"proxy_address": proxyAddress}, opts)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get proxy events: %v", err)
+    }
+    defer cursor.Close(ctx)
+
+    var events []*ProxyEvent
+    if err = cursor.All(ctx, &events); err != nil {
+        return nil, fmt.Errorf("failed to decode proxy events: %v", err)
+    }
+    return events, nil
+}
+
+// SaveProxySwap upserts swap by the Transfer log that pulled the input into the proxy
+func (m *MongoDB) SaveProxySwap(ctx context.Context, swap *ProxySwap) error {
+    _, err := m.proxySwapCollection.ReplaceOne(ctx,
+        eventKey(swap.ChainID, swap.TxHash, swap.LogIndex),
+        swap,
+        options.Replace().SetUpsert(true),
+    )
+    if err != nil {
+        return fmt.Errorf("failed to save proxy swap: %v", err)
+    }
+    return nil
+}
+
+// GetProxySwapsByUser returns the proxy swaps made by user, newest first,
+// optionally limited to one proxy and to confirmed swaps
+func (m *MongoDB) GetProxySwapsByUser(ctx context.Context, user, proxyAddress string, confirmedOnly bool) ([]*ProxySwap, error) {
+    filter := bson.M{"user": user}
+    if proxyAddress != "" {
+        filter["proxy_address"] = proxyAddress
+    }
+    if confirmedOnly {
+        filter["status"] = bson.M{"$ne": StatusPending}
+    }
+
+    opts := options.Find().SetSort(bson.D{{Key: "block_number", Value: -1}, {Key: "log_index", Value: -1}})
+    cursor, err := m.proxySwapCollection.Find(ctx, filter, opts)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get proxy swaps: %v", err)
+    }
+    defer cursor.Close(ctx)
+
+    var swaps []*ProxySwap
+    if err = cursor.All(ctx, &swaps); err != nil {
+        return nil, fmt.Errorf("failed to decode proxy swaps: %v", err)
+    }
+    return swaps, nil
+}
+
+// SaveTokenAdminEvent stores event under its eventKey
+func (m *MongoDB) SaveTokenAdminEvent(ctx context.Context, event *TokenAdminEvent) error {
+    _, err := m.adminCollection.ReplaceOne(ctx,
+        eventKey(event.ChainID, event.TxHash, event.LogIndex),
+        event,
+        options.Replace().SetUpsert(true),
+    )
+    if err != nil {
+        return fmt.Errorf("failed to save token admin event: %v", err)
+    }
+    return nil
+}
+
+// GetTokenAdminEvents returns the administrative history of a token, oldest first
+func (m *MongoDB) GetTokenAdminEvents(ctx context.Context, tokenAddress string, confirmedOnly bool) ([]*TokenAdminEvent, error) {
+    filter := bson.M{"token_address": tokenAddress}
+    if confirmedOnly {
+        filter["status"] = bson.M{"$ne": StatusPending}
+    }
+
+    opts := options.Find().SetSort(bson.D{{Key: "block_number", Value: 1}, {Key: "log_index", Value: 1}})
+    cursor, err := m.adminCollection.Find(ctx, filter, opts)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get token admin events: %v", err)
+    }
+    defer cursor.Close(ctx)
+
+    var events []*TokenAdminEvent
+    if err = cursor.All(ctx, &events); err != nil {
+        return nil, fmt.Errorf("failed to decode token admin events: %v", err)
+    }
+    return events, nil
+}
+
+// GetCheckpoint returns the stored cursor for a contract, or nil if none has been saved yet
+func (m *MongoDB) GetCheckpoint(ctx context.Context, contractAddress string) (*Checkpoint, error) {
+    var checkpoint Checkpoint
+    err := m.checkpointCollection.FindOne(ctx, bson.M{"contract_address": contractAddress}).Decode(&checkpoint)
+    if err == mongo.ErrNoDocuments {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to get checkpoint: %v", err)
+    }
+
+    return &checkpoint, nil
+}
+
+func (m *MongoDB) SaveCheckpoint(ctx context.Context, contractAddress string, lastBlock uint64) error {
+    _, err := m.checkpointCollection.UpdateOne(ctx,
+        bson.M{"contract_address": contractAddress},
+        bson.M{"$set": bson.M{
+            "last_block": lastBlock,
+            "updated_at": time.Now(),
+        }},
+        options.Update().SetUpsert(true),
+    )
+    if err != nil {
+        return fmt.Errorf("failed to save checkpoint: %v", err)
+    }
+    return nil
+}
+
+// SeedContract registers a contract unless it is already known, so entries
+// changed or deactivated through the admin API are left untouched
+func (m *MongoDB) SeedContract(ctx context.Context, contract *Contract) error {
+    now := time.Now()
+    _, err := m.contractCollection.UpdateOne(ctx,
+        bson.M{"address": contract.Address},
+        bson.M{"$setOnInsert": bson.M{
+            "address":          contract.Address,
+            "kind":             contract.Kind,
+            "name":             contract.Name,
+            "abi":              contract.ABI,
+            "token0_address":   contract.Token0Address,
+            "token1_address":   contract.Token1Address,
+            "deployment_block": contract.DeploymentBlock,
+            "block_hash":       contract.BlockHash,
+            "active":           true,
+            "created_at":       now,
+            "updated_at":       now,
+        }},
+        options.Update().SetUpsert(true),
+    )
+    if err != nil {
+        return fmt.Errorf("failed to seed contract: %v", err)
+    }
+    return nil
+}
+
+// SaveContract creates or replaces the registry entry for contract.Address
+func (m *MongoDB) SaveContract(ctx context.Context, contract *Contract) error {
+    now := time.Now()
+    if contract.CreatedAt.IsZero() {
+        contract.CreatedAt = now
+    }
+    contract.UpdatedAt = now
+
+    _, err := m.contractCollection.ReplaceOne(ctx,
+        bson.M{"address": contract.Address},
+        contract,
+        options.Replace().SetUpsert(true),
+    )
+    if err != nil {
+        return fmt.Errorf("failed to save contract: %v", err)
+    }
+    return nil
+}
+
+// GetContract returns the registry entry for an address, or nil if it is not registered
+func (m *MongoDB) GetContract(ctx context.Context, address string) (*Contract, error) {
+    var contract Contract
+    err := m.contractCollection.FindOne(ctx, bson.M{"address": address}).Decode(&contract)
+    if err == mongo.ErrNoDocuments {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to get contract: %v", err)
+    }
+
+    return &contract, nil
+}
+
+func (m *MongoDB) GetContracts(ctx context.Context, activeOnly bool) ([]*Contract, error) {
+    filter := bson.M{}
+    if activeOnly {
+        filter["active"] = true
+    }
+
+    cursor, err := m.contractCollection.Find(ctx, filter)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get contracts: %v", err)
+    }
+    defer cursor.Close(ctx)
+
+    var contracts []*Contract
+    if err = cursor.All(ctx, &contracts); err != nil {
+        return nil, fmt.Errorf("failed to decode contracts: %v", err)
+    }
+
+    return contracts, nil
+}
+
+// DeactivateContract stops a contract from being watched while keeping its
+// entry, so it is not re-seeded from the deployments directory on restart.
+// It reports whether the contract was registered.
+func (m *MongoDB) DeactivateContract(ctx context.Context, address string) (bool, error) {
+    result, err := m.contractCollection.UpdateOne(ctx,
+        bson.M{"address": address},
+        bson.M{"$set": bson.M{
+            "active":     false,
+            "updated_at": time.Now(),
+        }},
+    )
+    if err != nil {
+        return false, fmt.Errorf("failed to deactivate contract: %v", err)
+    }
+    return result.MatchedCount > 0, nil
+}
+
+// SavePool upserts a discovered pool by address
+func (m *MongoDB) SavePool(ctx context.Context, pool *Pool) error {
+    _, err := m.poolsCollection.ReplaceOne(ctx,
+        bson.M{"address": pool.Address},
+        pool,
+        options.Replace().SetUpsert(true),
+    )
+    if err != nil {
+        return fmt.Errorf("failed to save pool: %v", err)
+    }
+    return nil
+}
+
+// GetPool returns a discovered pool, or nil if the factory has not reported it
+func (m *MongoDB) GetPool(ctx context.Context, address string) (*Pool, error) {
+    var pool Pool
+    err := m.poolsCollection.FindOne(ctx, bson.M{"address": address}).Decode(&pool)
+    if err == mongo.ErrNoDocuments {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to get pool: %v", err)
+    }
+
+    return &pool, nil
+}
+
+// DeletePoolsByBlockHash removes the pools created in a block that is no longer
+// part of the canonical chain, along with their registry entries and
+// checkpoints, and returns the number of pools removed
+func (m *MongoDB) DeletePoolsByBlockHash(ctx context.Context, blockHash string) (int64, error) {
+    cursor, err := m.poolsCollection.Find(ctx, bson.M{"block_hash": blockHash})
+    if err != nil {
+        return 0, fmt.Errorf("failed to get orphaned pools: %v", err)
+    }
+    var pools []*Pool
+    if err = cursor.All(ctx, &pools); err != nil {
+        return 0, fmt.Errorf("failed to decode orphaned pools: %v", err)
+    }
+    if len(pools) == 0 {
+        return 0, nil
+    }
+
+    addresses := make(bson.A, 0, len(pools))
+    for _, pool := range pools {
+        addresses = append(addresses, pool.Address)
+    }
+
+    // Contracts registered by other means are kept even if a pool was also discovered at that address
+    _, err = m.contractCollection.DeleteMany(ctx, bson.M{"address": bson.M{"$in": addresses}, "block_hash": blockHash})
+    if err != nil {
+        return 0, fmt.Errorf("failed to delete orphaned pool contracts: %v", err)
+    }
+
+    _, err = m.checkpointCollection.DeleteMany(ctx, bson.M{"contract_address": bson.M{"$in": addresses}})
+    if err != nil {
+        return 0, fmt.Errorf("failed to delete orphaned pool checkpoints: %v", err)
+    }
+
+    result, err := m.poolsCollection.DeleteMany(ctx, bson.M{"block_hash": blockHash})
+    if err != nil {
+        return 0, fmt.Errorf("failed to delete orphaned pools: %v", err)
+    }
+    return result.DeletedCount, nil
+}
+
+// GetTokenMetadata returns the stored metadata of a token, or nil if it has not been fetched yet
+func (m *MongoDB) GetTokenMetadata(ctx context.Context, address string) (*TokenMetadata, error) {
+    var metadata TokenMetadata
+    err := m.tokenCollection.FindOne(ctx, bson.M{"address": address}).Decode(&metadata)
+    if err == mongo.ErrNoDocuments {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to get token metadata: %v", err)
+    }
+
+    return &metadata, nil
+}
+
+func (m *MongoDB) SaveTokenMetadata(ctx context.Context, metadata *TokenMetadata) error {
+    _, err := m.tokenCollection.ReplaceOne(ctx,
+        bson.M{"address": metadata.Address},
+        metadata,
+        options.Replace().SetUpsert(true),
+    )
+    if err != nil {
+        return fmt.Errorf("failed to save token metadata: %v", err)
+    }
+    return nil
 }
\ No newline at end of file
@@ -0,0 +1,220 @@
+package database
+
+import (
+    "context"
+    "fmt"
+    "os"
+    "testing"
+    "time"
+
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+
+    "src/internal/config"
+)
+
+// newTestDB returns a fresh database on the MongoDB at MONGODB_TEST_URI, which
+// is dropped when the test ends. Tests are skipped without one.
+func newTestDB(t *testing.T) Service {
+    uri := os.Getenv("MONGODB_TEST_URI")
+    if uri == "" {
+        t.Skip("MONGODB_TEST_URI is not set")
+    }
+
+    name := fmt.Sprintf("database_test_%d", time.Now().UnixNano())
+    db := New(config.DatabaseConfig{URI: uri, Name: name})
+    t.Cleanup(func() {
+        ctx := context.Background()
+        if client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri)); err == nil {
+            client.Database(name).Drop(ctx)
+            client.Disconnect(ctx)
+        }
+        db.Close(ctx)
+    })
+    return db
+}
+
+const (
+    testToken = "0x1000000000000000000000000000000000000001"
+    testPool  = "0x2000000000000000000000000000000000000002"
+    alice     = "0xA11CE00000000000000000000000000000000000"
+    bob       = "0xB0B0000000000000000000000000000000000000"
+)
+
+var testTime = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func transfer(from, to, amount string, block uint64, blockHash string) *Transaction {
+    return &Transaction{
+        FromAddress:  from,
+        ToAddress:    to,
+        TokenAddress: testToken,
+        Amount:       amount,
+        ChainID:      1,
+        TxHash:       fmt.Sprintf("0x%064x", block),
+        EventType:    "Transfer",
+        Timestamp:    testTime,
+        BlockNumber:  block,
+        BlockHash:    blockHash,
+        Status:       StatusPending,
+    }
+}
+
+func poolEvent(eventType, amount0, amount1, sqrtPriceX96 string, block uint64, logIndex uint, blockHash string) *PoolTransaction {
+    return &PoolTransaction{
+        PoolAddress:  testPool,
+        EventType:    eventType,
+        Amount0:      amount0,
+        Amount1:      amount1,
+        SqrtPriceX96: sqrtPriceX96,
+        ChainID:      1,
+        TxHash:       fmt.Sprintf("0x%064x", block),
+        LogIndex:     logIndex,
+        BlockNumber:  block,
+        BlockHash:    blockHash,
+        Timestamp:    testTime,
+        Status:       StatusPending,
+    }
+}
+
+func balanceOf(t *testing.T, db Service, holder string) string {
+    t.Helper()
+    balances, err := db.GetBalances(context.Background(), holder)
+    if err != nil {
+        t.Fatal(err)
+    }
+    for _, balance := range balances {
+        if balance.TokenAddress == testToken {
+            return balance.Balance
+        }
+    }
+    return "0"
+}
+
+// Re-saving a transfer, as after a backfill overlap, must not move the balance again
+func TestSaveTransactionAppliesTransferOnce(t *testing.T) {
+    db := newTestDB(t)
+    ctx := context.Background()
+
+    for _, tx := range []*Transaction{
+        transfer(zeroAddress, alice, "1000", 1, "0x01"),
+        transfer(alice, bob, "300", 2, "0x02"),
+        transfer(alice, bob, "300", 2, "0x02"),
+    } {
+        if err := db.SaveTransaction(ctx, tx); err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    if got := balanceOf(t, db, alice); got != "700" {
+        t.Errorf("alice's balance = %s, want 700", got)
+    }
+    if got := balanceOf(t, db, bob); got != "300" {
+        t.Errorf("bob's balance = %s, want 300", got)
+    }
+}
+
+func TestDeleteEventsByBlockHashRevertsBalances(t *testing.T) {
+    db := newTestDB(t)
+    ctx := context.Background()
+
+    for _, tx := range []*Transaction{
+        transfer(zeroAddress, alice, "1000", 1, "0x01"),
+        transfer(alice, bob, "300", 2, "0x02"),
+    } {
+        if err := db.SaveTransaction(ctx, tx); err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    deleted, err := db.DeleteEventsByBlockHash(ctx, "0x02")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if deleted != 1 {
+        t.Errorf("deleted %d events, want 1", deleted)
+    }
+
+    if got := balanceOf(t, db, alice); got != "1000" {
+        t.Errorf("alice's balance = %s, want 1000", got)
+    }
+    if got := balanceOf(t, db, bob); got != "0" {
+        t.Errorf("bob's balance = %s, want 0", got)
+    }
+    transfers, err := db.GetTransactionsByToken(ctx, testToken)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(transfers) != 1 || transfers[0].BlockHash != "0x01" {
+        t.Errorf("transfers after the rollback = %+v, want only block 0x01", transfers)
+    }
+}
+
+func TestDeleteEventsByBlockHashRevertsReserves(t *testing.T) {
+    db := newTestDB(t)
+    ctx := context.Background()
+
+    for _, tx := range []*PoolTransaction{
+        poolEvent("Mint", "1000", "2000", "", 1, 0, "0x01"),
+        poolEvent("Swap", "100", "-50", "79228162514264337593543950336", 2, 0, "0x02"),
+        poolEvent("Swap", "10", "-5", "158456325028528675187087900672", 3, 0, "0x03"),
+        poolEvent("Collect", "5", "5", "", 3, 1, "0x03"),
+    } {
+        if err := db.SavePoolTransaction(ctx, tx); err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    if _, err := db.DeleteEventsByBlockHash(ctx, "0x03"); err != nil {
+        t.Fatal(err)
+    }
+
+    reserves, err := db.GetPoolReserves(ctx, testPool)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if reserves == nil || reserves.Reserve0 != "1100" || reserves.Reserve1 != "1950" {
+        t.Errorf("reserves = %+v, want 1100/1950", reserves)
+    }
+}
+
+// Removing a swap that set a candle's high must lower it again
+func TestDeleteEventsByBlockHashRebuildsCandles(t *testing.T) {
+    db := newTestDB(t)
+    ctx := context.Background()
+
+    const (
+        priceStart = "79228162514264337593543950336"
+        priceHigh  = "158456325028528675187087900672"
+        priceLast  = "71305346262837903834189555302"
+    )
+    for _, tx := range []*PoolTransaction{
+        poolEvent("Swap", "100", "-50", priceStart, 1, 0, "0x01"),
+        poolEvent("Swap", "10", "-5", priceHigh, 2, 0, "0x02"),
+        poolEvent("Swap", "-20", "40", priceLast, 3, 0, "0x03"),
+    } {
+        if err := db.SavePoolTransaction(ctx, tx); err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    if _, err := db.DeleteEventsByBlockHash(ctx, "0x02"); err != nil {
+        t.Fatal(err)
+    }
+
+    for interval := range CandleIntervals {
+        candles, err := db.GetCandles(ctx, testPool, interval, testTime.Add(-48*time.Hour), testTime.Add(48*time.Hour))
+        if err != nil {
+            t.Fatal(err)
+        }
+        if len(candles) != 1 {
+            t.Fatalf("got %d %s candles, want 1", len(candles), interval)
+        }
+
+        candle := candles[0]
+        want := Candle{Open: priceStart, High: priceStart, Low: priceLast, Close: priceLast, Volume0: "100", Volume1: "40", Swaps: 2}
+        if candle.Open != want.Open || candle.High != want.High || candle.Low != want.Low || candle.Close != want.Close ||
+            candle.Volume0 != want.Volume0 || candle.Volume1 != want.Volume1 || candle.Swaps != want.Swaps {
+            t.Errorf("%s candle = %+v, want %+v", interval, candle, want)
+        }
+    }
+}
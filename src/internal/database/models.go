@@ -5,16 +5,36 @@ import (
     "go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Kinds of contracts in the registry
+const (
+    ContractKindERC20   = "erc20"
+    ContractKindV3Pool  = "v3-pool"
+    ContractKindProxy   = "proxy"
+    ContractKindFactory = "factory"
+)
+
+// Finality status of stored events
+const (
+    StatusPending   = "pending"
+    StatusConfirmed = "confirmed"
+)
+
 type Transaction struct {
     ID            primitive.ObjectID `bson:"_id,omitempty"`
     AccountAddress string           `bson:"account_address"`
+    FromAddress   string           `bson:"from_address"`
+    ToAddress     string           `bson:"to_address"`
     TokenAddress  string           `bson:"token_address"`
     Amount        string           `bson:"amount"`
+    ChainID       uint64           `bson:"chain_id"`
     TxHash        string           `bson:"tx_hash"`
-    EventType     string           `bson:"event_type"` // "Mint" or "Burn"
-    Timestamp     time.Time        `bson:"timestamp"`
+    LogIndex      uint             `bson:"log_index"`
+    EventType     string           `bson:"event_type"` // "Mint", "Burn" or "Transfer"
+    Timestamp     time.Time        `bson:"timestamp"` // Block timestamp
+    IndexedAt     time.Time        `bson:"indexed_at,omitempty"`
     BlockNumber   uint64           `bson:"block_number"`
     BlockHash     string           `bson:"block_hash"`
+    Status        string           `bson:"status"` // "pending" or "confirmed"
 }
 
 // Model for pool events
@@ -25,14 +45,192 @@ type PoolTransaction struct {
     Token1Address string            `bson:"token1_address"`
     EventType     string            `bson:"event_type"`
     Sender        string            `bson:"sender"`
+    Owner         string            `bson:"owner,omitempty"`
     Recipient     string            `bson:"recipient,omitempty"`
     Amount0       string            `bson:"amount0"`
     Amount1       string            `bson:"amount1"`
     SqrtPriceX96  string            `bson:"sqrt_price_x96,omitempty"`
     Liquidity     string            `bson:"liquidity,omitempty"`
     Tick          int               `bson:"tick,omitempty"`
+    TickLower     int               `bson:"tick_lower,omitempty"`
+    TickUpper     int               `bson:"tick_upper,omitempty"`
+    Paid0         string            `bson:"paid0,omitempty"`
+    Paid1         string            `bson:"paid1,omitempty"`
+    ChainID       uint64            `bson:"chain_id"`
     TxHash        string            `bson:"tx_hash"`
+    LogIndex      uint              `bson:"log_index"`
     BlockNumber   uint64            `bson:"block_number"`
     BlockHash     string            `bson:"block_hash"`
-    Timestamp     time.Time         `bson:"timestamp"`
+    Timestamp     time.Time         `bson:"timestamp"` // Block timestamp
+    IndexedAt     time.Time         `bson:"indexed_at,omitempty"`
+    Status        string            `bson:"status"` // "pending" or "confirmed"
+}
+
+// ProxyEvent is an EIP-1967 Upgraded, AdminChanged or BeaconUpgraded event of a proxy
+type ProxyEvent struct {
+    ID             primitive.ObjectID `bson:"_id,omitempty"`
+    ProxyAddress   string             `bson:"proxy_address"`
+    EventType      string             `bson:"event_type"`
+    Implementation string             `bson:"implementation,omitempty"`
+    Version        string             `bson:"version,omitempty"` // version() of the implementation, if it has one
+    PreviousAdmin  string             `bson:"previous_admin,omitempty"`
+    NewAdmin       string             `bson:"new_admin,omitempty"`
+    Beacon         string             `bson:"beacon,omitempty"`
+    ChainID        uint64             `bson:"chain_id"`
+    TxHash         string             `bson:"tx_hash"`
+    LogIndex       uint               `bson:"log_index"`
+    BlockNumber    uint64             `bson:"block_number"`
+    BlockHash      string             `bson:"block_hash"`
+    Timestamp      time.Time          `bson:"timestamp"` // Block timestamp
+    IndexedAt      time.Time          `bson:"indexed_at,omitempty"`
+    Status         string             `bson:"status"` // "pending" or "confirmed"
+}
+
+// ProxySwap is a swap made by calling a UniswapProxy swap function directly,
+// attributed to the caller rather than the router the pool sees
+type ProxySwap struct {
+    ID                 primitive.ObjectID `bson:"_id,omitempty"`
+    ProxyAddress       string             `bson:"proxy_address"`
+    User               string             `bson:"user"`
+    Mode               string             `bson:"mode"` // "exact-in" or "exact-out"
+    TokenIn            string             `bson:"token_in"`
+    TokenOut           string             `bson:"token_out"`
+    PoolAddress        string             `bson:"pool_address,omitempty"`
+    AmountIn           string             `bson:"amount_in"` // Spent, net of the refund
+    AmountOut          string             `bson:"amount_out"`
+    AmountOutRequested string             `bson:"amount_out_requested,omitempty"` // exact-out only
+    AmountInMaximum    string             `bson:"amount_in_maximum,omitempty"`    // exact-out only
+    Refund             string             `bson:"refund"`
+    Implementation     string             `bson:"implementation,omitempty"`
+    Version            string             `bson:"version,omitempty"`
+    ChainID            uint64             `bson:"chain_id"`
+    TxHash             string             `bson:"tx_hash"`
+    LogIndex           uint               `bson:"log_index"` // Of the Transfer into the proxy
+    BlockNumber        uint64             `bson:"block_number"`
+    BlockHash          string             `bson:"block_hash"`
+    Timestamp          time.Time          `bson:"timestamp"` // Block timestamp
+    IndexedAt          time.Time          `bson:"indexed_at,omitempty"`
+    Status             string             `bson:"status"` // "pending" or "confirmed"
+}
+
+// TokenAdminEvent is a Paused, Unpaused, Blacklisted, UnBlacklisted or
+// OwnershipTransferred event of a token
+type TokenAdminEvent struct {
+    ID            primitive.ObjectID `bson:"_id,omitempty"`
+    TokenAddress  string             `bson:"token_address"`
+    EventType     string             `bson:"event_type"`
+    Account       string             `bson:"account,omitempty"` // Pauser, or the (un)blacklisted account
+    PreviousOwner string             `bson:"previous_owner,omitempty"`
+    NewOwner      string             `bson:"new_owner,omitempty"`
+    ChainID       uint64             `bson:"chain_id"`
+    TxHash        string             `bson:"tx_hash"`
+    LogIndex      uint               `bson:"log_index"`
+    BlockNumber   uint64             `bson:"block_number"`
+    BlockHash     string             `bson:"block_hash"`
+    Timestamp     time.Time          `bson:"timestamp"` // Block timestamp
+    IndexedAt     time.Time          `bson:"indexed_at,omitempty"`
+    Status        string             `bson:"status"` // "pending" or "confirmed"
+}
+
+// Checkpoint records the last block whose events have been fully processed for a contract
+type Checkpoint struct {
+    ID              primitive.ObjectID `bson:"_id,omitempty"`
+    ContractAddress string             `bson:"contract_address"`
+    LastBlock       uint64             `bson:"last_block"`
+    UpdatedAt       time.Time          `bson:"updated_at"`
+}
+
+// Contract is an entry in the registry of contracts watched by the listener
+type Contract struct {
+    ID              primitive.ObjectID `bson:"_id,omitempty"`
+    Address         string             `bson:"address"`
+    Kind            string             `bson:"kind"`
+    Name            string             `bson:"name,omitempty"`
+    ABI             string             `bson:"abi,omitempty"`
+    Token0Address   string             `bson:"token0_address,omitempty"` // Pools only
+    Token1Address   string             `bson:"token1_address,omitempty"`
+    DeploymentBlock uint64             `bson:"deployment_block"`
+    BlockHash       string             `bson:"block_hash,omitempty"` // Pools discovered from a factory only
+    Active          bool               `bson:"active"`
+    CreatedAt       time.Time          `bson:"created_at"`
+    UpdatedAt       time.Time          `bson:"updated_at"`
+}
+
+// Pool is a Uniswap V3 pool discovered from a factory's PoolCreated event
+type Pool struct {
+    ID             primitive.ObjectID `bson:"_id,omitempty"`
+    Address        string             `bson:"address"`
+    FactoryAddress string             `bson:"factory_address"`
+    Token0Address  string             `bson:"token0_address"`
+    Token1Address  string             `bson:"token1_address"`
+    Fee            uint32             `bson:"fee"` // In hundredths of a basis point, e.g. 3000 = 0.3%
+    TickSpacing    int                `bson:"tick_spacing"`
+    ChainID        uint64             `bson:"chain_id"`
+    TxHash         string             `bson:"tx_hash"`
+    BlockNumber    uint64             `bson:"block_number"`
+    BlockHash      string             `bson:"block_hash"`
+    CreatedAt      time.Time          `bson:"created_at"`
+}
+
+// Balance is the running balance of a holder for a token, maintained from its Transfer events
+type Balance struct {
+    ID           primitive.ObjectID `bson:"_id,omitempty"`
+    TokenAddress string             `bson:"token_address"`
+    Holder       string             `bson:"holder"`
+    Balance      string             `bson:"balance"`
+    BlockNumber  uint64             `bson:"block_number"` // Last block that changed the balance
+    UpdatedAt    time.Time          `bson:"updated_at"`
+}
+
+// PoolReserves is the running token balance of a pool, maintained from its
+// Mint, Swap, Collect and Flash events
+type PoolReserves struct {
+    ID            primitive.ObjectID `bson:"_id,omitempty"`
+    PoolAddress   string             `bson:"pool_address"`
+    Token0Address string             `bson:"token0_address"`
+    Token1Address string             `bson:"token1_address"`
+    Reserve0      string             `bson:"reserve0"`
+    Reserve1      string             `bson:"reserve1"`
+    BlockNumber   uint64             `bson:"block_number"` // Last block that changed the reserves
+    UpdatedAt     time.Time          `bson:"updated_at"`
+}
+
+// CandleIntervals are the candle sizes aggregated for every pool
+var CandleIntervals = map[string]time.Duration{
+    "1m": time.Minute,
+    "5m": 5 * time.Minute,
+    "1h": time.Hour,
+    "1d": 24 * time.Hour,
+}
+
+// Candle aggregates the swaps of a pool within one interval. Prices are kept
+// as sqrtPriceX96, which orders the same way as the price, so the candle does
+// not depend on token decimals.
+type Candle struct {
+    ID            primitive.ObjectID `bson:"_id,omitempty"`
+    PoolAddress   string             `bson:"pool_address"`
+    Interval      string             `bson:"interval"`
+    OpenTime      time.Time          `bson:"open_time"`
+    Open          string             `bson:"open"`
+    High          string             `bson:"high"`
+    Low           string             `bson:"low"`
+    Close         string             `bson:"close"`
+    Volume0       string             `bson:"volume0"` // Amounts paid into the pool
+    Volume1       string             `bson:"volume1"`
+    Swaps         int64              `bson:"swaps"`
+    OpenBlock     uint64             `bson:"open_block"` // Position of the first and last swap
+    OpenLogIndex  uint               `bson:"open_log_index"`
+    CloseBlock    uint64             `bson:"close_block"`
+    CloseLogIndex uint               `bson:"close_log_index"`
+    UpdatedAt     time.Time          `bson:"updated_at"`
+}
+
+// TokenMetadata caches the ERC-20 metadata needed to format amounts
+type TokenMetadata struct {
+    ID        primitive.ObjectID `bson:"_id,omitempty"`
+    Address   string             `bson:"address"`
+    Name      string             `bson:"name"`
+    Symbol    string             `bson:"symbol"`
+    Decimals  uint8              `bson:"decimals"`
+    UpdatedAt time.Time          `bson:"updated_at"`
 }
\ No newline at end of file
@@ -2,14 +2,42 @@ package database
 
 import (
     "context"
+    "time"
 )
 
 type Service interface {
     Health() map[string]string
     SaveTransaction(ctx context.Context, tx *Transaction) error
-    GetTransactionsByAccount(ctx context.Context, accountAddress string) ([]*Transaction, error)
+    GetTransactionsByAccount(ctx context.Context, accountAddress string, confirmedOnly bool) ([]*Transaction, error)
     GetTransactionsByToken(ctx context.Context, tokenAddress string) ([]*Transaction, error)
     SavePoolTransaction(ctx context.Context, tx *PoolTransaction) error
-    GetPoolTransactions(ctx context.Context, poolAddress string) ([]*PoolTransaction, error)
+    GetPoolTransactions(ctx context.Context, poolAddress string, confirmedOnly bool) ([]*PoolTransaction, error)
+    GetLatestSwap(ctx context.Context, poolAddress string) (*PoolTransaction, error)
+    GetSwapBefore(ctx context.Context, poolAddress string, t time.Time) (*PoolTransaction, error)
+    GetSwaps(ctx context.Context, poolAddress string, from, to time.Time) ([]*PoolTransaction, error)
+    GetPositionEvents(ctx context.Context, poolAddress string) ([]*PoolTransaction, error)
+    GetPoolReserves(ctx context.Context, poolAddress string) (*PoolReserves, error)
+    GetCandles(ctx context.Context, poolAddress, interval string, from, to time.Time) ([]*Candle, error)
+    SaveProxyEvent(ctx context.Context, event *ProxyEvent) error
+    GetProxyEvents(ctx context.Context, proxyAddress string) ([]*ProxyEvent, error)
+    SaveProxySwap(ctx context.Context, swap *ProxySwap) error
+    GetProxySwapsByUser(ctx context.Context, user, proxyAddress string, confirmedOnly bool) ([]*ProxySwap, error)
+    SaveTokenAdminEvent(ctx context.Context, event *TokenAdminEvent) error
+    GetTokenAdminEvents(ctx context.Context, tokenAddress string, confirmedOnly bool) ([]*TokenAdminEvent, error)
+    ConfirmEvents(ctx context.Context, upToBlock uint64) (int64, error)
+    DeleteEventsByBlockHash(ctx context.Context, blockHash string) (int64, error)
+    GetCheckpoint(ctx context.Context, contractAddress string) (*Checkpoint, error)
+    SaveCheckpoint(ctx context.Context, contractAddress string, lastBlock uint64) error
+    SeedContract(ctx context.Context, contract *Contract) error
+    SaveContract(ctx context.Context, contract *Contract) error
+    GetContract(ctx context.Context, address string) (*Contract, error)
+    GetContracts(ctx context.Context, activeOnly bool) ([]*Contract, error)
+    DeactivateContract(ctx context.Context, address string) (bool, error)
+    SavePool(ctx context.Context, pool *Pool) error
+    GetPool(ctx context.Context, address string) (*Pool, error)
+    DeletePoolsByBlockHash(ctx context.Context, blockHash string) (int64, error)
+    GetBalances(ctx context.Context, holder string) ([]*Balance, error)
+    GetTokenMetadata(ctx context.Context, address string) (*TokenMetadata, error)
+    SaveTokenMetadata(ctx context.Context, metadata *TokenMetadata) error
     Close(ctx context.Context) error
 }
\ No newline at end of file
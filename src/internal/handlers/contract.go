@@ -0,0 +1,102 @@
+package handlers
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "time"
+    "github.com/gin-gonic/gin"
+)
+
+// ErrNotFound is returned by services when the requested resource does not exist
+var ErrNotFound = errors.New("not found")
+
+// ErrInvalidInput wraps validation errors that should be reported as bad requests
+var ErrInvalidInput = errors.New("invalid input")
+
+type ContractInfo struct {
+    Address         string    `json:"address"`
+    Kind            string    `json:"kind"`
+    Name            string    `json:"name,omitempty"`
+    Token0Address   string    `json:"token0_address,omitempty"`
+    Token1Address   string    `json:"token1_address,omitempty"`
+    DeploymentBlock uint64    `json:"deployment_block"`
+    Active          bool      `json:"active"`
+    UpdatedAt       time.Time `json:"updated_at"`
+}
+
+type RegisterContractRequest struct {
+    Address         string `json:"address" binding:"required"`
+    Kind            string `json:"kind" binding:"required"`
+    Name            string `json:"name"`
+    DeploymentBlock uint64 `json:"deployment_block"`
+}
+
+type ContractService interface {
+    ListContracts(ctx context.Context) ([]ContractInfo, error)
+    RegisterContract(ctx context.Context, req RegisterContractRequest) (*ContractInfo, error)
+    RemoveContract(ctx context.Context, address string) error
+}
+
+type ContractHandler struct {
+    service ContractService
+}
+
+func NewContractHandler(service ContractService) *ContractHandler {
+    return &ContractHandler{
+        service: service,
+    }
+}
+
+func (h *ContractHandler) ListContracts(c *gin.Context) {
+    contracts, err := h.service.ListContracts(c.Request.Context())
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, contracts)
+}
+
+func (h *ContractHandler) RegisterContract(c *gin.Context) {
+    var req RegisterContractRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    contract, err := h.service.RegisterContract(c.Request.Context(), req)
+    if err != nil {
+        writeError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusCreated, contract)
+}
+
+func (h *ContractHandler) RemoveContract(c *gin.Context) {
+    address := c.Param("address")
+    if address == "" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "address is required"})
+        return
+    }
+
+    if err := h.service.RemoveContract(c.Request.Context(), address); err != nil {
+        writeError(c, err)
+        return
+    }
+
+    c.Status(http.StatusNoContent)
+}
+
+// writeError maps service errors to HTTP status codes
+func writeError(c *gin.Context, err error) {
+    switch {
+    case errors.Is(err, ErrInvalidInput):
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    case errors.Is(err, ErrNotFound):
+        c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+    default:
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+    }
+}
@@ -2,7 +2,10 @@ package handlers
 
 import (
     "context"
+    "fmt"
     "net/http"
+    "strconv"
+    "strings"
     "time"
     "github.com/gin-gonic/gin"
 )
@@ -11,17 +14,142 @@ type PoolStatus struct {
     PoolAddress    string    `json:"pool_address"`
     Token0Address  string    `json:"token0_address"`
     Token1Address  string    `json:"token1_address"`
-    CurrentPrice   string    `json:"current_price"`
-    TVL           string    `json:"tvl"`
-    Volume24h     string    `json:"volume_24h"`
+    CurrentPrice   string    `json:"current_price"`  // Same as token0_price
+    Token0Price    string    `json:"token0_price"`   // Price of one token0 in token1
+    Token1Price    string    `json:"token1_price"`   // Price of one token1 in token0
+    SqrtPriceX96   string    `json:"sqrt_price_x96"`
+    Tick           int       `json:"tick"`
+    PriceSource    string    `json:"price_source"`   // "swap" or "slot0"
+    Reserve0       string    `json:"reserve0"`
+    Reserve1       string    `json:"reserve1"`
+    Reserve0Raw    string    `json:"reserve0_raw"`
+    Reserve1Raw    string    `json:"reserve1_raw"`
+    ReservesVerified bool    `json:"reserves_verified"` // Indexed reserves match balanceOf(pool)
+    TVL           string    `json:"tvl"`      // In tvl_unit
+    TVLUnit       string    `json:"tvl_unit"` // Quote token when one is configured and priceable, token1 otherwise
+    TVLToken0     string    `json:"tvl_token0"`
+    TVLToken1     string    `json:"tvl_token1"`
+    Volume24h     string    `json:"volume_24h"`      // Both tokens in volume_24h_unit, empty when the pool has no price
+    Volume24hUnit string    `json:"volume_24h_unit"` // Same as tvl_unit
+    Volume        []PoolVolume `json:"volume"`
+    FeeTier       uint32    `json:"fee_tier"` // In hundredths of a basis point
     TotalSwaps    int64     `json:"total_swaps"`
     TotalMints    int64     `json:"total_mints"`
     TotalBurns    int64     `json:"total_burns"`
+    Finality      string    `json:"finality"`
     LastUpdated   time.Time `json:"last_updated"`
 }
 
+// PoolVolume is the swap volume and fee revenue of a pool over a trailing window.
+// Volume counts the amounts paid into the pool, so each swap adds to the token sold.
+type PoolVolume struct {
+    Window    string `json:"window"`
+    Swaps     int64  `json:"swaps"`
+    Token0    string `json:"token0"`
+    Token1    string `json:"token1"`
+    Token0Raw string `json:"token0_raw"`
+    Token1Raw string `json:"token1_raw"`
+    Value     string `json:"value"` // Both tokens in unit
+    Fees0     string `json:"fees0"`
+    Fees1     string `json:"fees1"`
+    Fees0Raw  string `json:"fees0_raw"`
+    Fees1Raw  string `json:"fees1_raw"`
+    FeesValue string `json:"fees_value"`
+    Unit      string `json:"unit"`
+}
+
+// Candle prices are token1 per token0; volumes are the amounts paid into the pool
+type Candle struct {
+    OpenTime time.Time `json:"open_time"`
+    Open     string    `json:"open"`
+    High     string    `json:"high"`
+    Low      string    `json:"low"`
+    Close    string    `json:"close"`
+    Volume0  string    `json:"volume0"`
+    Volume1  string    `json:"volume1"`
+    Swaps    int64     `json:"swaps"`
+}
+
+type CandleResponse struct {
+    PoolAddress   string    `json:"pool_address"`
+    Token0Address string    `json:"token0_address"`
+    Token1Address string    `json:"token1_address"`
+    Interval      string    `json:"interval"`
+    From          time.Time `json:"from"`
+    To            time.Time `json:"to"`
+    Candles       []*Candle `json:"candles"`
+}
+
+// LiquidityTick is an initialized tick; Liquidity is active from this tick up to the next
+type LiquidityTick struct {
+    Tick           int    `json:"tick"`
+    Price          string `json:"price"`
+    LiquidityNet   string `json:"liquidity_net"`
+    LiquidityGross string `json:"liquidity_gross"`
+    Liquidity      string `json:"liquidity"`
+}
+
+// LiquidityDepth is the token0 available before the price rises by Percent and
+// the token1 available before it falls by Percent
+type LiquidityDepth struct {
+    Percent   float64 `json:"percent"`
+    Token0    string  `json:"token0"`
+    Token1    string  `json:"token1"`
+    Token0Raw string  `json:"token0_raw"`
+    Token1Raw string  `json:"token1_raw"`
+}
+
+// LiquidityDistribution is a pool's liquidity rebuilt from its Mint and Burn events
+type LiquidityDistribution struct {
+    PoolAddress     string           `json:"pool_address"`
+    Token0Address   string           `json:"token0_address"`
+    Token1Address   string           `json:"token1_address"`
+    CurrentTick     int              `json:"current_tick"`
+    SqrtPriceX96    string           `json:"sqrt_price_x96"`
+    Price           string           `json:"price"` // token1 per token0
+    PriceSource     string           `json:"price_source"`
+    ActiveLiquidity string           `json:"active_liquidity"`
+    Ticks           []LiquidityTick  `json:"ticks"`
+    Depth           []LiquidityDepth `json:"depth"`
+}
+
+// Quote is a swap simulated against the pool's indexed liquidity. Amounts are
+// formatted with token decimals, the *Raw fields are base units.
+type Quote struct {
+    PoolAddress       string        `json:"pool_address"`
+    TokenIn           string        `json:"token_in"`
+    TokenOut          string        `json:"token_out"`
+    ExactInput        bool          `json:"exact_input"`
+    AmountIn          string        `json:"amount_in"` // Including the fee
+    AmountOut         string        `json:"amount_out"`
+    AmountInRaw       string        `json:"amount_in_raw"`
+    AmountOutRaw      string        `json:"amount_out_raw"`
+    FeeTier           uint32        `json:"fee_tier"`
+    FeeAmountRaw      string        `json:"fee_amount_raw"`
+    PriceBefore       string        `json:"price_before"` // token1 per token0
+    PriceAfter        string        `json:"price_after"`
+    PriceImpact       string        `json:"price_impact"` // Percent, excluding the fee
+    SqrtPriceX96After string        `json:"sqrt_price_x96_after"`
+    TickAfter         int           `json:"tick_after"`
+    TicksCrossed      int           `json:"ticks_crossed"` // Initialized ticks
+    Partial           bool          `json:"partial"`       // Liquidity ran out before the amount was filled
+    OnChain           *OnChainQuote `json:"on_chain,omitempty"`
+}
+
+// OnChainQuote is the same swap quoted by QuoterV2 through eth_call. Amount
+// is the output for exact input quotes and the input for exact output quotes.
+type OnChainQuote struct {
+    Amount            string `json:"amount,omitempty"`
+    SqrtPriceX96After string `json:"sqrt_price_x96_after,omitempty"`
+    Matches           bool   `json:"matches"`
+    Error             string `json:"error,omitempty"`
+}
+
 type PoolService interface {
-    GetPoolStatus(ctx context.Context, poolAddress string) (*PoolStatus, error)
+    GetPoolStatus(ctx context.Context, poolAddress string, finality string) (*PoolStatus, error)
+    GetCandles(ctx context.Context, poolAddress, interval string, from, to time.Time) (*CandleResponse, error)
+    GetLiquidity(ctx context.Context, poolAddress string, depths []float64) (*LiquidityDistribution, error)
+    GetQuote(ctx context.Context, poolAddress, tokenIn, amountIn, amountOut string, verify bool) (*Quote, error)
 }
 
 type PoolHandler struct {
@@ -41,11 +169,98 @@ func (h *PoolHandler) GetPoolStatus(c *gin.Context) {
         return
     }
 
-    status, err := h.service.GetPoolStatus(c.Request.Context(), poolAddress)
+    finality, ok := parseFinality(c)
+    if !ok {
+        return
+    }
+
+    status, err := h.service.GetPoolStatus(c.Request.Context(), poolAddress, finality)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        writeError(c, err)
         return
     }
 
     c.JSON(http.StatusOK, status)
-}
\ No newline at end of file
+}
+
+// GetCandles serves OHLCV candles. from and to are unix timestamps; to
+// defaults to now and from to 500 intervals earlier.
+func (h *PoolHandler) GetCandles(c *gin.Context) {
+    poolAddress := c.Param("address")
+    interval := c.DefaultQuery("interval", "1h")
+
+    from, ok := parseUnixTime(c, "from")
+    if !ok {
+        return
+    }
+    to, ok := parseUnixTime(c, "to")
+    if !ok {
+        return
+    }
+
+    candles, err := h.service.GetCandles(c.Request.Context(), poolAddress, interval, from, to)
+    if err != nil {
+        writeError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, candles)
+}
+
+// GetLiquidity serves the liquidity curve with the depth at each of the comma
+// separated depth percentages (default 1,2,5)
+func (h *PoolHandler) GetLiquidity(c *gin.Context) {
+    poolAddress := c.Param("address")
+
+    var depths []float64
+    for _, value := range strings.Split(c.DefaultQuery("depth", "1,2,5"), ",") {
+        percent, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid depth %q, expected a percentage", value)})
+            return
+        }
+        depths = append(depths, percent)
+    }
+
+    distribution, err := h.service.GetLiquidity(c.Request.Context(), poolAddress, depths)
+    if err != nil {
+        writeError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, distribution)
+}
+
+// GetQuote serves a simulated swap of token_in with either amount_in or
+// amount_out in base units. verify=true compares it with the on-chain quoter.
+func (h *PoolHandler) GetQuote(c *gin.Context) {
+    verify, err := strconv.ParseBool(c.DefaultQuery("verify", "false"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "verify must be true or false"})
+        return
+    }
+
+    quote, err := h.service.GetQuote(c.Request.Context(), c.Param("address"), c.Query("token_in"), c.Query("amount_in"), c.Query("amount_out"), verify)
+    if err != nil {
+        writeError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, quote)
+}
+
+// parseUnixTime reads an optional unix timestamp query parameter, returning the
+// zero time when it is absent, and writes a bad request response when it is invalid
+func parseUnixTime(c *gin.Context, name string) (time.Time, bool) {
+    value := c.Query(name)
+    if value == "" {
+        return time.Time{}, true
+    }
+
+    seconds, err := strconv.ParseInt(value, 10, 64)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid %s %q, expected a unix timestamp", name, value)})
+        return time.Time{}, false
+    }
+    return time.Unix(seconds, 0).UTC(), true
+}
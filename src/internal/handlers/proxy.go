@@ -0,0 +1,136 @@
+package handlers
+
+import (
+    "context"
+    "net/http"
+    "time"
+    "github.com/gin-gonic/gin"
+)
+
+// ProxyHistory is the upgrade and admin history of an EIP-1967 proxy along
+// with the state it leaves the proxy in
+type ProxyHistory struct {
+    ProxyAddress   string         `json:"proxy_address"`
+    Implementation string         `json:"implementation,omitempty"`
+    Version        string         `json:"version,omitempty"` // Reported by the current implementation's version()
+    Admin          string         `json:"admin,omitempty"`
+    Beacon         string         `json:"beacon,omitempty"`
+    Upgrades       int            `json:"upgrades"`
+    Events         []ProxyUpgrade `json:"events"` // Oldest first
+}
+
+// ProxyUpgrade is a single Upgraded, AdminChanged or BeaconUpgraded event
+type ProxyUpgrade struct {
+    EventType      string    `json:"event_type"`
+    Implementation string    `json:"implementation,omitempty"`
+    Version        string    `json:"version,omitempty"`
+    PreviousAdmin  string    `json:"previous_admin,omitempty"`
+    NewAdmin       string    `json:"new_admin,omitempty"`
+    Beacon         string    `json:"beacon,omitempty"`
+    BlockNumber    uint64    `json:"block_number"`
+    TxHash         string    `json:"tx_hash"`
+    LogIndex       uint      `json:"log_index"`
+    Timestamp      time.Time `json:"timestamp"`
+    Status         string    `json:"status"`
+}
+
+// ProxyInspection is what a proxy delegates to according to its EIP-1967
+// storage slots at the latest block
+type ProxyInspection struct {
+    ProxyAddress          string   `json:"proxy_address"`
+    Implementation        string   `json:"implementation,omitempty"`
+    Admin                 string   `json:"admin,omitempty"`
+    Beacon                string   `json:"beacon,omitempty"`
+    CodeHash              string   `json:"code_hash,omitempty"` // keccak256 of the implementation's runtime bytecode
+    Version               string   `json:"version,omitempty"`   // Empty when the implementation has no version()
+    Contract              string   `json:"contract,omitempty"`  // Compiled artifact the code matches, e.g. UniswapProxyV2
+    Known                 bool     `json:"known"`
+    IndexedImplementation string   `json:"indexed_implementation,omitempty"` // From the last indexed Upgraded event
+    MatchesIndexed        *bool    `json:"matches_indexed,omitempty"`
+    Warnings              []string `json:"warnings,omitempty"`
+}
+
+// ProxySwap is a swap made through a UniswapProxy, attributed to the caller.
+// Amounts are formatted with the token's decimals, the *Raw fields are in base units.
+type ProxySwap struct {
+    ProxyAddress          string    `json:"proxy_address"`
+    Mode                  string    `json:"mode"` // exact-in or exact-out
+    TokenIn               string    `json:"token_in"`
+    TokenOut              string    `json:"token_out"`
+    PoolAddress           string    `json:"pool_address,omitempty"`
+    AmountIn              string    `json:"amount_in"`
+    AmountInRaw           string    `json:"amount_in_raw"`
+    AmountOut             string    `json:"amount_out"`
+    AmountOutRaw          string    `json:"amount_out_raw"`
+    AmountInMaximumRaw    string    `json:"amount_in_maximum_raw,omitempty"`    // exact-out only
+    AmountOutRequestedRaw string    `json:"amount_out_requested_raw,omitempty"` // exact-out only
+    Refund                string    `json:"refund"`
+    RefundRaw             string    `json:"refund_raw"`
+    Implementation        string    `json:"implementation,omitempty"`
+    Version               string    `json:"version,omitempty"`
+    TxHash                string    `json:"tx_hash"`
+    BlockNumber           uint64    `json:"block_number"`
+    Timestamp             time.Time `json:"timestamp"`
+    Status                string    `json:"status"`
+}
+
+// ProxySwapHistory lists a user's proxy swaps, newest first
+type ProxySwapHistory struct {
+    User     string      `json:"user"`
+    Count    int         `json:"count"`
+    Swaps    []ProxySwap `json:"swaps"`
+    Finality string      `json:"finality"`
+}
+
+type ProxyService interface {
+    GetProxyHistory(ctx context.Context, proxyAddress string) (*ProxyHistory, error)
+    InspectProxy(ctx context.Context, proxyAddress string) (*ProxyInspection, error)
+    GetUserSwaps(ctx context.Context, user, proxyAddress, finality string) (*ProxySwapHistory, error)
+}
+
+type ProxyHandler struct {
+    service ProxyService
+}
+
+func NewProxyHandler(service ProxyService) *ProxyHandler {
+    return &ProxyHandler{
+        service: service,
+    }
+}
+
+func (h *ProxyHandler) GetProxyHistory(c *gin.Context) {
+    history, err := h.service.GetProxyHistory(c.Request.Context(), c.Param("address"))
+    if err != nil {
+        writeError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, history)
+}
+
+func (h *ProxyHandler) InspectProxy(c *gin.Context) {
+    inspection, err := h.service.InspectProxy(c.Request.Context(), c.Param("address"))
+    if err != nil {
+        writeError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, inspection)
+}
+
+// GetUserSwaps serves the swaps a user made through any registered proxy, or
+// through the proxy given by the proxy query parameter
+func (h *ProxyHandler) GetUserSwaps(c *gin.Context) {
+    finality, ok := parseFinality(c)
+    if !ok {
+        return
+    }
+
+    history, err := h.service.GetUserSwaps(c.Request.Context(), c.Param("address"), c.Query("proxy"), finality)
+    if err != nil {
+        writeError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, history)
+}
@@ -1,7 +1,14 @@
 package handlers
 
 import (
-    
+    "net/http"
+    "github.com/gin-gonic/gin"
+)
+
+// Accepted values of the finality query parameter
+const (
+    FinalityLatest    = "latest"
+    FinalityConfirmed = "confirmed"
 )
 
 type TransactionResponse struct {
@@ -17,17 +24,45 @@ type TransactionResponse struct {
     BlockHash     string    `json:"block_hash"`
 }
 
+// Amounts are formatted with the token's decimals; the *Raw fields hold the
+// same amounts in base units, comparable with balanceOf
 type TokenSummary struct {
-    TokenAddress   string `json:"token_address"`
-    TotalMinted   string `json:"total_minted"`
-    TotalBurned   string `json:"total_burned"`
-    CurrentBalance string `json:"current_balance"`
+    TokenAddress      string `json:"token_address"`
+    Symbol            string `json:"symbol,omitempty"`
+    Name              string `json:"name,omitempty"`
+    Decimals          uint8  `json:"decimals"`
+    TotalMinted       string `json:"total_minted"`
+    TotalMintedRaw    string `json:"total_minted_raw"`
+    TotalBurned       string `json:"total_burned"`
+    TotalBurnedRaw    string `json:"total_burned_raw"`
+    TotalReceived     string `json:"total_received"`
+    TotalReceivedRaw  string `json:"total_received_raw"`
+    TotalSent         string `json:"total_sent"`
+    TotalSentRaw      string `json:"total_sent_raw"`
+    CurrentBalance    string `json:"current_balance"`
+    CurrentBalanceRaw string `json:"current_balance_raw"`
+    BalanceBlock      uint64 `json:"balance_block,omitempty"` // Block the balance was last changed in
 }
 
+// Account-wide totals add up all tokens, each normalised to 18 decimals
 type AccountSummary struct {
     AccountAddress string         `json:"account_address"`
     Tokens        []TokenSummary `json:"tokens"`
     TotalMinted   string         `json:"total_minted"`
     TotalBurned   string         `json:"total_burned"`
+    TotalReceived string         `json:"total_received"`
+    TotalSent     string         `json:"total_sent"`
     NetBalance    string         `json:"net_balance"`
+    Finality      string         `json:"finality"`
+}
+
+// parseFinality reads the finality query parameter, defaulting to latest,
+// and writes a bad request response when the value is not supported
+func parseFinality(c *gin.Context) (string, bool) {
+    finality := c.DefaultQuery("finality", FinalityLatest)
+    if finality != FinalityLatest && finality != FinalityConfirmed {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "finality must be one of: confirmed, latest"})
+        return "", false
+    }
+    return finality, true
 }
\ No newline at end of file
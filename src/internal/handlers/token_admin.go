@@ -0,0 +1,69 @@
+package handlers
+
+import (
+    "context"
+    "net/http"
+    "time"
+    "github.com/gin-gonic/gin"
+)
+
+// TokenAdminState is a token's pause state, owner and blacklist as
+// reconstructed from its administrative events
+type TokenAdminState struct {
+    TokenAddress string            `json:"token_address"`
+    Symbol       string            `json:"symbol,omitempty"`
+    Paused       bool              `json:"paused"`
+    Owner        string            `json:"owner,omitempty"` // Empty until an OwnershipTransferred event is indexed
+    Blacklist    []BlacklistEntry  `json:"blacklist"`
+    History      []TokenAdminEvent `json:"history"` // Oldest first
+    Finality     string            `json:"finality"`
+}
+
+// BlacklistEntry is a currently blacklisted account and when it was listed
+type BlacklistEntry struct {
+    Account     string    `json:"account"`
+    BlockNumber uint64    `json:"block_number"`
+    TxHash      string    `json:"tx_hash"`
+    Timestamp   time.Time `json:"timestamp"`
+}
+
+type TokenAdminEvent struct {
+    EventType     string    `json:"event_type"`
+    Account       string    `json:"account,omitempty"`
+    PreviousOwner string    `json:"previous_owner,omitempty"`
+    NewOwner      string    `json:"new_owner,omitempty"`
+    BlockNumber   uint64    `json:"block_number"`
+    TxHash        string    `json:"tx_hash"`
+    LogIndex      uint      `json:"log_index"`
+    Timestamp     time.Time `json:"timestamp"`
+    Status        string    `json:"status"`
+}
+
+type TokenAdminService interface {
+    GetTokenAdminState(ctx context.Context, tokenAddress, finality string) (*TokenAdminState, error)
+}
+
+type TokenAdminHandler struct {
+    service TokenAdminService
+}
+
+func NewTokenAdminHandler(service TokenAdminService) *TokenAdminHandler {
+    return &TokenAdminHandler{
+        service: service,
+    }
+}
+
+func (h *TokenAdminHandler) GetTokenAdminState(c *gin.Context) {
+    finality, ok := parseFinality(c)
+    if !ok {
+        return
+    }
+
+    state, err := h.service.GetTokenAdminState(c.Request.Context(), c.Param("address"), finality)
+    if err != nil {
+        writeError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, state)
+}
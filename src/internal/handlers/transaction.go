@@ -7,7 +7,7 @@ import (
 )
 
 type TransactionService interface {
-    GetAccountSummary(ctx context.Context, accountAddress string) (*AccountSummary, error)
+    GetAccountSummary(ctx context.Context, accountAddress string, finality string) (*AccountSummary, error)
 }
 
 type TransactionHandler struct {
@@ -27,9 +27,14 @@ func (h *TransactionHandler) GetAccountSummary(c *gin.Context) {
         return
     }
 
-    summary, err := h.service.GetAccountSummary(c.Request.Context(), accountAddress)
+    finality, ok := parseFinality(c)
+    if !ok {
+        return
+    }
+
+    summary, err := h.service.GetAccountSummary(c.Request.Context(), accountAddress, finality)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        writeError(c, err)
         return
     }
 
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+    "context"
+    "net/http"
+    "strconv"
+    "time"
+    "github.com/gin-gonic/gin"
+)
+
+// TWAP is the geometric time-weighted average price of a pool, 1.0001 raised
+// to the arithmetic mean tick, as the pool's own oracle defines it
+type TWAP struct {
+    PoolAddress    string       `json:"pool_address"`
+    Token0Address  string       `json:"token0_address"`
+    Token1Address  string       `json:"token1_address"`
+    Window         string       `json:"window"`
+    WindowSeconds  int64        `json:"window_seconds"`
+    From           time.Time    `json:"from"`
+    To             time.Time    `json:"to"`
+    CoveredSeconds int64        `json:"covered_seconds"` // Less than window_seconds when the indexed history is shorter
+    Swaps          int          `json:"swaps"`
+    MeanTick       int          `json:"mean_tick"`
+    Price          string       `json:"price"`         // token1 per token0
+    InversePrice   string       `json:"inverse_price"` // token0 per token1
+    Oracle         *OracleTWAP  `json:"oracle,omitempty"`
+}
+
+// OracleTWAP is the same average read from the pool's observe() oracle
+type OracleTWAP struct {
+    MeanTick      int    `json:"mean_tick"`
+    Price         string `json:"price"`
+    TickDeviation int    `json:"tick_deviation"` // Indexed mean tick minus oracle mean tick
+    Error         string `json:"error,omitempty"`
+}
+
+type TWAPService interface {
+    GetTWAP(ctx context.Context, poolAddress, window string, verify bool) (*TWAP, error)
+}
+
+type TWAPHandler struct {
+    service TWAPService
+}
+
+func NewTWAPHandler(service TWAPService) *TWAPHandler {
+    return &TWAPHandler{
+        service: service,
+    }
+}
+
+// GetTWAP serves the TWAP over window (default 1h). With verify=true the
+// result is cross-checked against the pool's observe() oracle.
+func (h *TWAPHandler) GetTWAP(c *gin.Context) {
+    poolAddress := c.Param("address")
+    window := c.DefaultQuery("window", "1h")
+
+    verify, err := strconv.ParseBool(c.DefaultQuery("verify", "false"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "verify must be true or false"})
+        return
+    }
+
+    twap, err := h.service.GetTWAP(c.Request.Context(), poolAddress, window, verify)
+    if err != nil {
+        writeError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, twap)
+}
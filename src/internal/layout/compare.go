@@ -0,0 +1,233 @@
+package layout
+
+import (
+    "fmt"
+    "math/big"
+    "strings"
+)
+
+// Change kinds. Only removed, retyped, reordered, moved and inserted variables
+// are unsafe; the rest leave every existing variable where it was.
+const (
+    ChangeRemoved    = "removed"
+    ChangeRetyped    = "retyped"
+    ChangeReordered  = "reordered"
+    ChangeMoved      = "moved"    // Same order, but shifted by a change before it
+    ChangeInserted   = "inserted" // New variable placed before or between existing ones
+    ChangeRenamed    = "renamed"  // Same slot and type under a new name
+    ChangeGapResized = "gap-resized"
+    ChangeAppended   = "appended"
+)
+
+// Change is a difference between the old and new layout of one variable
+type Change struct {
+    Kind     string `json:"kind"`
+    Variable string `json:"variable"` // Contract.label
+    Old      string `json:"old,omitempty"`
+    New      string `json:"new,omitempty"`
+}
+
+func (c Change) Unsafe() bool {
+    switch c.Kind {
+    case ChangeRemoved, ChangeRetyped, ChangeReordered, ChangeMoved, ChangeInserted:
+        return true
+    }
+    return false
+}
+
+func (c Change) String() string {
+    switch {
+    case c.Old != "" && c.New != "":
+        return fmt.Sprintf("%-11s %s: %s -> %s", c.Kind, c.Variable, c.Old, c.New)
+    case c.Old != "":
+        return fmt.Sprintf("%-11s %s: %s", c.Kind, c.Variable, c.Old)
+    }
+    return fmt.Sprintf("%-11s %s: %s", c.Kind, c.Variable, c.New)
+}
+
+// variable is a storage variable with its resolved type and byte range
+type variable struct {
+    Variable
+    name      string // Contract.label
+    signature string
+    start     *big.Int // slot * 32 + offset
+    end       *big.Int
+}
+
+func (v *variable) position() string {
+    return fmt.Sprintf("%s at slot %s offset %d", v.signature, v.Slot, v.Offset)
+}
+
+// isGap matches the __gap arrays upgradeable contracts reserve slots with
+func (v *variable) isGap() bool {
+    return strings.HasPrefix(v.Label, "__gap") && strings.HasSuffix(v.signature, "]")
+}
+
+func (l *Layout) variables() ([]*variable, error) {
+    vars := make([]*variable, 0, len(l.Storage))
+    for _, v := range l.Storage {
+        slot, ok := new(big.Int).SetString(v.Slot, 10)
+        if !ok {
+            return nil, fmt.Errorf("%s: invalid slot %q for %s", l.Name, v.Slot, v.Label)
+        }
+        size, ok := new(big.Int).SetString(l.Types[v.Type].NumberOfBytes, 10)
+        if !ok {
+            return nil, fmt.Errorf("%s: unknown size of type %s of %s", l.Name, v.Type, v.Label)
+        }
+
+        start := slot.Mul(slot, big.NewInt(32))
+        start.Add(start, new(big.Int).SetUint64(v.Offset))
+        vars = append(vars, &variable{
+            Variable:  v,
+            name:      contractName(v.Contract) + "." + v.Label,
+            signature: l.signature(v.Type),
+            start:     start,
+            end:       new(big.Int).Add(start, size),
+        })
+    }
+    return vars, nil
+}
+
+// signature describes a type independently of AST ids, including the layout
+// of struct members
+func (l *Layout) signature(id string) string {
+    t, ok := l.Types[id]
+    if !ok {
+        return id
+    }
+
+    switch {
+    case t.Encoding == "mapping":
+        return fmt.Sprintf("mapping(%s => %s)", l.signature(t.Key), l.signature(t.Value))
+    case t.Encoding == "dynamic_array":
+        return l.signature(t.Base) + "[]"
+    case len(t.Members) > 0:
+        members := make([]string, 0, len(t.Members))
+        for _, member := range t.Members {
+            members = append(members, fmt.Sprintf("%s %s@%s:%d", l.signature(member.Type), member.Label, member.Slot, member.Offset))
+        }
+        return fmt.Sprintf("%s{%s}", t.Label, strings.Join(members, "; "))
+    case t.Base != "":
+        // Static array, the length is the last bracket of the label
+        return l.signature(t.Base) + t.Label[strings.LastIndex(t.Label, "["):]
+    }
+    return t.Label
+}
+
+func contractName(qualified string) string {
+    if i := strings.LastIndex(qualified, ":"); i >= 0 {
+        return qualified[i+1:]
+    }
+    return qualified
+}
+
+// Compare reports how the storage of newLayout differs from oldLayout.
+// Variables are matched by declaring contract and name, as solc never lets a
+// derived contract redeclare an inherited state variable.
+func Compare(oldLayout, newLayout *Layout) ([]Change, error) {
+    oldVars, err := oldLayout.variables()
+    if err != nil {
+        return nil, err
+    }
+    newVars, err := newLayout.variables()
+    if err != nil {
+        return nil, err
+    }
+
+    newIndex := make(map[string]int, len(newVars))
+    for i, v := range newVars {
+        newIndex[v.name] = i
+    }
+    oldNames := make(map[string]bool, len(oldVars))
+    for _, v := range oldVars {
+        oldNames[v.name] = true
+    }
+
+    var changes []Change
+    matched := make(map[int]bool)
+    // Ranges freed by shrinking a gap, which new variables may use
+    var released [][2]*big.Int
+    oldEnd := new(big.Int)
+    lastIndex := -1
+
+    for _, ov := range oldVars {
+        if ov.end.Cmp(oldEnd) > 0 {
+            oldEnd.Set(ov.end)
+        }
+
+        j, ok := newIndex[ov.name]
+        if !ok {
+            if j, nv := renamed(ov, newVars, oldNames); nv != nil {
+                matched[j] = true
+                changes = append(changes, Change{Kind: ChangeRenamed, Variable: ov.name, Old: ov.Label, New: nv.Label})
+                continue
+            }
+            changes = append(changes, Change{Kind: ChangeRemoved, Variable: ov.name, Old: ov.position()})
+            continue
+        }
+        nv := newVars[j]
+        matched[j] = true
+
+        if ov.isGap() && nv.isGap() && ov.signature != nv.signature {
+            // Shrinking a gap from the front is how new variables are added
+            // to a base contract; the gap must still end where it did
+            if nv.end.Cmp(ov.end) == 0 && nv.start.Cmp(ov.start) >= 0 {
+                released = append(released, [2]*big.Int{ov.start, nv.start})
+                changes = append(changes, Change{Kind: ChangeGapResized, Variable: ov.name, Old: ov.position(), New: nv.position()})
+            } else {
+                changes = append(changes, Change{Kind: ChangeRetyped, Variable: ov.name, Old: ov.position(), New: nv.position()})
+            }
+            lastIndex = max(lastIndex, j)
+            continue
+        }
+
+        switch {
+        case ov.signature != nv.signature:
+            changes = append(changes, Change{Kind: ChangeRetyped, Variable: ov.name, Old: ov.position(), New: nv.position()})
+        case j < lastIndex:
+            changes = append(changes, Change{Kind: ChangeReordered, Variable: ov.name, Old: ov.position(), New: nv.position()})
+        case ov.start.Cmp(nv.start) != 0:
+            changes = append(changes, Change{Kind: ChangeMoved, Variable: ov.name, Old: ov.position(), New: nv.position()})
+        }
+        lastIndex = max(lastIndex, j)
+    }
+
+    for j, nv := range newVars {
+        if matched[j] {
+            continue
+        }
+        switch {
+        case nv.start.Cmp(oldEnd) >= 0:
+            changes = append(changes, Change{Kind: ChangeAppended, Variable: nv.name, New: nv.position()})
+        case within(nv, released):
+            changes = append(changes, Change{Kind: ChangeAppended, Variable: nv.name, New: nv.position() + " (in gap)"})
+        default:
+            changes = append(changes, Change{Kind: ChangeInserted, Variable: nv.name, New: nv.position()})
+        }
+    }
+
+    return changes, nil
+}
+
+// renamed finds a variable that is new in the new layout but occupies the
+// same bytes with the same type as ov
+func renamed(ov *variable, newVars []*variable, oldNames map[string]bool) (int, *variable) {
+    for j, nv := range newVars {
+        if oldNames[nv.name] {
+            continue
+        }
+        if nv.start.Cmp(ov.start) == 0 && nv.signature == ov.signature && contractName(nv.Contract) == contractName(ov.Contract) {
+            return j, nv
+        }
+    }
+    return -1, nil
+}
+
+func within(v *variable, ranges [][2]*big.Int) bool {
+    for _, r := range ranges {
+        if v.start.Cmp(r[0]) >= 0 && v.end.Cmp(r[1]) <= 0 {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,221 @@
+package layout
+
+import "testing"
+
+const (
+    baseContract    = "contracts/Base.sol:Base"
+    derivedContract = "contracts/Vault.sol:Vault"
+)
+
+var testTypes = map[string]Type{
+    "t_address": {Encoding: "inplace", Label: "address", NumberOfBytes: "20"},
+    "t_bool":    {Encoding: "inplace", Label: "bool", NumberOfBytes: "1"},
+    "t_uint128": {Encoding: "inplace", Label: "uint128", NumberOfBytes: "16"},
+    "t_uint256": {Encoding: "inplace", Label: "uint256", NumberOfBytes: "32"},
+    "t_array(t_uint256)48_storage": {Encoding: "inplace", Label: "uint256[48]", NumberOfBytes: "1536", Base: "t_uint256"},
+    "t_array(t_uint256)47_storage": {Encoding: "inplace", Label: "uint256[47]", NumberOfBytes: "1504", Base: "t_uint256"},
+    "t_mapping(t_address,t_uint256)": {Encoding: "mapping", Label: "mapping(address => uint256)", NumberOfBytes: "32", Key: "t_address", Value: "t_uint256"},
+}
+
+func base(label, slot string, offset uint64, typ string) Variable {
+    return Variable{Contract: baseContract, Label: label, Slot: slot, Offset: offset, Type: typ}
+}
+
+func derived(label, slot string, offset uint64, typ string) Variable {
+    return Variable{Contract: derivedContract, Label: label, Slot: slot, Offset: offset, Type: typ}
+}
+
+// testLayout is a base contract reserving slots 2-49 with a gap and a derived
+// contract storing balances after it
+func testLayout(storage ...Variable) *Layout {
+    if storage == nil {
+        storage = []Variable{
+            base("owner", "0", 0, "t_address"),
+            base("paused", "0", 20, "t_bool"),
+            base("fee", "1", 0, "t_uint256"),
+            base("__gap", "2", 0, "t_array(t_uint256)48_storage"),
+            derived("balances", "50", 0, "t_mapping(t_address,t_uint256)"),
+        }
+    }
+    return &Layout{Name: "Vault", Storage: storage, Types: testTypes}
+}
+
+func TestCompare(t *testing.T) {
+    tests := []struct {
+        name   string
+        layout *Layout
+        want   []Change // Kind and Variable only
+        unsafe bool
+    }{
+        {
+            name:   "unchanged",
+            layout: testLayout(),
+        },
+        {
+            name: "appended",
+            layout: testLayout(
+                base("owner", "0", 0, "t_address"),
+                base("paused", "0", 20, "t_bool"),
+                base("fee", "1", 0, "t_uint256"),
+                base("__gap", "2", 0, "t_array(t_uint256)48_storage"),
+                derived("balances", "50", 0, "t_mapping(t_address,t_uint256)"),
+                derived("total", "51", 0, "t_uint256"),
+            ),
+            want: []Change{{Kind: ChangeAppended, Variable: "Vault.total"}},
+        },
+        {
+            name: "inserted",
+            layout: testLayout(
+                base("owner", "0", 0, "t_address"),
+                base("paused", "0", 20, "t_bool"),
+                base("fee", "1", 0, "t_uint256"),
+                base("__gap", "2", 0, "t_array(t_uint256)48_storage"),
+                derived("total", "50", 0, "t_uint256"),
+                derived("balances", "51", 0, "t_mapping(t_address,t_uint256)"),
+            ),
+            want: []Change{
+                {Kind: ChangeMoved, Variable: "Vault.balances"},
+                {Kind: ChangeInserted, Variable: "Vault.total"},
+            },
+            unsafe: true,
+        },
+        {
+            name: "removed",
+            layout: testLayout(
+                base("owner", "0", 0, "t_address"),
+                base("fee", "1", 0, "t_uint256"),
+                base("__gap", "2", 0, "t_array(t_uint256)48_storage"),
+                derived("balances", "50", 0, "t_mapping(t_address,t_uint256)"),
+            ),
+            want:   []Change{{Kind: ChangeRemoved, Variable: "Base.paused"}},
+            unsafe: true,
+        },
+        {
+            name: "retyped",
+            layout: testLayout(
+                base("owner", "0", 0, "t_address"),
+                base("paused", "0", 20, "t_bool"),
+                base("fee", "1", 0, "t_uint128"),
+                base("__gap", "2", 0, "t_array(t_uint256)48_storage"),
+                derived("balances", "50", 0, "t_mapping(t_address,t_uint256)"),
+            ),
+            want:   []Change{{Kind: ChangeRetyped, Variable: "Base.fee"}},
+            unsafe: true,
+        },
+        {
+            name: "reordered",
+            layout: testLayout(
+                base("paused", "0", 0, "t_bool"),
+                base("owner", "0", 1, "t_address"),
+                base("fee", "1", 0, "t_uint256"),
+                base("__gap", "2", 0, "t_array(t_uint256)48_storage"),
+                derived("balances", "50", 0, "t_mapping(t_address,t_uint256)"),
+            ),
+            want: []Change{
+                {Kind: ChangeMoved, Variable: "Base.owner"},
+                {Kind: ChangeReordered, Variable: "Base.paused"},
+            },
+            unsafe: true,
+        },
+        {
+            name: "moved",
+            layout: testLayout(
+                base("owner", "0", 0, "t_address"),
+                base("paused", "0", 20, "t_bool"),
+                base("fee", "2", 0, "t_uint256"),
+                base("__gap", "3", 0, "t_array(t_uint256)47_storage"),
+                derived("balances", "50", 0, "t_mapping(t_address,t_uint256)"),
+            ),
+            want: []Change{
+                {Kind: ChangeMoved, Variable: "Base.fee"},
+                {Kind: ChangeGapResized, Variable: "Base.__gap"},
+            },
+            unsafe: true,
+        },
+        {
+            name: "gap shrunk for a new base variable",
+            layout: testLayout(
+                base("owner", "0", 0, "t_address"),
+                base("paused", "0", 20, "t_bool"),
+                base("fee", "1", 0, "t_uint256"),
+                base("limit", "2", 0, "t_uint256"),
+                base("__gap", "3", 0, "t_array(t_uint256)47_storage"),
+                derived("balances", "50", 0, "t_mapping(t_address,t_uint256)"),
+            ),
+            want: []Change{
+                {Kind: ChangeGapResized, Variable: "Base.__gap"},
+                {Kind: ChangeAppended, Variable: "Base.limit"},
+            },
+        },
+        {
+            name: "gap shrunk from the end",
+            layout: testLayout(
+                base("owner", "0", 0, "t_address"),
+                base("paused", "0", 20, "t_bool"),
+                base("fee", "1", 0, "t_uint256"),
+                base("__gap", "2", 0, "t_array(t_uint256)47_storage"),
+                derived("balances", "50", 0, "t_mapping(t_address,t_uint256)"),
+            ),
+            want:   []Change{{Kind: ChangeRetyped, Variable: "Base.__gap"}},
+            unsafe: true,
+        },
+        {
+            name: "renamed",
+            layout: testLayout(
+                base("admin", "0", 0, "t_address"),
+                base("paused", "0", 20, "t_bool"),
+                base("fee", "1", 0, "t_uint256"),
+                base("__gap", "2", 0, "t_array(t_uint256)48_storage"),
+                derived("balances", "50", 0, "t_mapping(t_address,t_uint256)"),
+            ),
+            want: []Change{{Kind: ChangeRenamed, Variable: "Base.owner"}},
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            changes, err := Compare(testLayout(), tt.layout)
+            if err != nil {
+                t.Fatal(err)
+            }
+
+            if len(changes) != len(tt.want) {
+                t.Fatalf("Compare() = %v, want %v", changes, tt.want)
+            }
+            unsafe := false
+            for i, change := range changes {
+                if change.Kind != tt.want[i].Kind || change.Variable != tt.want[i].Variable {
+                    t.Errorf("change %d = %s %s, want %s %s", i, change.Kind, change.Variable, tt.want[i].Kind, tt.want[i].Variable)
+                }
+                unsafe = unsafe || change.Unsafe()
+            }
+            if unsafe != tt.unsafe {
+                t.Errorf("Unsafe() = %v, want %v for %v", unsafe, tt.unsafe, changes)
+            }
+        })
+    }
+}
+
+func TestChangeUnsafe(t *testing.T) {
+    for kind, want := range map[string]bool{
+        ChangeRemoved:    true,
+        ChangeRetyped:    true,
+        ChangeReordered:  true,
+        ChangeMoved:      true,
+        ChangeInserted:   true,
+        ChangeRenamed:    false,
+        ChangeGapResized: false,
+        ChangeAppended:   false,
+    } {
+        if got := (Change{Kind: kind}).Unsafe(); got != want {
+            t.Errorf("Change{Kind: %q}.Unsafe() = %v, want %v", kind, got, want)
+        }
+    }
+}
+
+func TestCompareInvalidSlot(t *testing.T) {
+    broken := testLayout(base("owner", "0x0", 0, "t_address"))
+    if _, err := Compare(testLayout(), broken); err == nil {
+        t.Fatal("Compare() accepted a non-decimal slot")
+    }
+}
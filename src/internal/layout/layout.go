@@ -0,0 +1,96 @@
+package layout
+
+import (
+    "encoding/json"
+    "fmt"
+    "os"
+    "path/filepath"
+    "strings"
+)
+
+// Variable is a state variable in solc's storageLayout output
+type Variable struct {
+    Contract string `json:"contract"` // Declaring contract, e.g. contracts/UniswapProxy.sol:UniswapProxy
+    Label    string `json:"label"`
+    Offset   uint64 `json:"offset"` // Byte offset within the slot
+    Slot     string `json:"slot"`   // Decimal string, slots can exceed 64 bits
+    Type     string `json:"type"`
+}
+
+// Type describes a storage type. Type ids embed AST ids, which change between
+// compilations, so types are compared through their labels and members.
+type Type struct {
+    Encoding      string     `json:"encoding"` // inplace, mapping, dynamic_array or bytes
+    Label         string     `json:"label"`
+    NumberOfBytes string     `json:"numberOfBytes"`
+    Base          string     `json:"base,omitempty"`
+    Key           string     `json:"key,omitempty"`
+    Value         string     `json:"value,omitempty"`
+    Members       []Variable `json:"members,omitempty"`
+}
+
+// Layout is the storage layout of a single contract
+type Layout struct {
+    Name    string
+    Storage []Variable      `json:"storage"`
+    Types   map[string]Type `json:"types"`
+}
+
+// debugFile is the <Name>.dbg.json Hardhat writes next to every artifact
+type debugFile struct {
+    BuildInfo string `json:"buildInfo"`
+}
+
+type buildInfo struct {
+    Output struct {
+        Contracts map[string]map[string]struct {
+            StorageLayout *Layout `json:"storageLayout"`
+        } `json:"contracts"`
+    } `json:"output"`
+}
+
+// Load reads a contract's storage layout from the build info referenced by
+// its Hardhat artifact. name is either a bare contract name, compiled from
+// contracts/<name>.sol, or a fully qualified name such as
+// contracts/UniswapProxyV2.sol:UniswapProxyV2. solc only emits the layout
+// when storageLayout is in the output selection, which
+// @openzeppelin/hardhat-upgrades enables.
+func Load(artifactsDir, name string) (*Layout, error) {
+    sourceName, contractName, ok := strings.Cut(name, ":")
+    if !ok {
+        contractName = name
+        sourceName = "contracts/" + name + ".sol"
+    }
+
+    debugPath := filepath.Join(artifactsDir, filepath.FromSlash(sourceName), contractName+".dbg.json")
+    data, err := os.ReadFile(debugPath)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read %s: %v", debugPath, err)
+    }
+    var debug debugFile
+    if err := json.Unmarshal(data, &debug); err != nil {
+        return nil, fmt.Errorf("failed to parse %s: %v", debugPath, err)
+    }
+
+    // The build info path is relative to the debug file
+    buildInfoPath := filepath.Join(filepath.Dir(debugPath), filepath.FromSlash(debug.BuildInfo))
+    data, err = os.ReadFile(buildInfoPath)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read build info %s: %v", buildInfoPath, err)
+    }
+    var info buildInfo
+    if err := json.Unmarshal(data, &info); err != nil {
+        return nil, fmt.Errorf("failed to parse build info %s: %v", buildInfoPath, err)
+    }
+
+    contract, ok := info.Output.Contracts[sourceName][contractName]
+    if !ok {
+        return nil, fmt.Errorf("%s:%s not found in build info %s", sourceName, contractName, buildInfoPath)
+    }
+    if contract.StorageLayout == nil {
+        return nil, fmt.Errorf("build info %s has no storage layout for %s; add storageLayout to the solc output selection", buildInfoPath, contractName)
+    }
+
+    contract.StorageLayout.Name = contractName
+    return contract.StorageLayout, nil
+}
@@ -1,6 +1,8 @@
 package server
 
 import (
+    "crypto/subtle"
+    "log"
     "net/http"
     "github.com/gin-gonic/gin"
     "src/internal/handlers"
@@ -11,16 +13,67 @@ func (s *Server) RegisterRoutes() http.Handler {
     r := gin.Default()
 
     // Initialize services
-    txService := services.NewTransactionService(s.db)
-    poolService := services.NewPoolService(s.db)
+    tokens := services.NewTokenRegistry(s.db, s.chain)
+    txService := services.NewTransactionService(s.db, tokens)
+    poolService := services.NewPoolService(s.db, tokens, s.chain, s.pricing)
+    twapService := services.NewTWAPService(s.db, tokens, s.chain)
+    proxyService := services.NewProxyService(s.db, tokens, s.chain, s.implementations)
+    tokenAdminService := services.NewTokenAdminService(s.db, tokens)
+    contractService := services.NewContractService(s.db, s.listener)
     
     // Initialize handlers
     txHandler := handlers.NewTransactionHandler(txService)
     poolHandler := handlers.NewPoolHandler(poolService)
+    twapHandler := handlers.NewTWAPHandler(twapService)
+    proxyHandler := handlers.NewProxyHandler(proxyService)
+    tokenAdminHandler := handlers.NewTokenAdminHandler(tokenAdminService)
+    contractHandler := handlers.NewContractHandler(contractService)
 
     // Register routes
+    r.GET("/health", s.healthHandler)
     r.GET("/transactions/summary/:address", txHandler.GetAccountSummary)
     r.GET("/pool/status/:address", poolHandler.GetPoolStatus)
+    r.GET("/pool/:address/candles", poolHandler.GetCandles)
+    r.GET("/pool/:address/twap", twapHandler.GetTWAP)
+    r.GET("/pool/:address/liquidity", poolHandler.GetLiquidity)
+    r.GET("/pool/:address/quote", poolHandler.GetQuote)
+    r.GET("/proxy/:address/history", proxyHandler.GetProxyHistory)
+    r.GET("/proxy/:address/inspect", proxyHandler.InspectProxy)
+    r.GET("/proxy/swaps/:address", proxyHandler.GetUserSwaps)
+    r.GET("/tokens/:address/admin", tokenAdminHandler.GetTokenAdminState)
+
+    // The registry can only be changed by clients holding the admin token
+    if s.adminToken == "" {
+        log.Println("No admin token configured, /admin routes are disabled")
+    } else {
+        admin := r.Group("/admin", s.requireAdmin)
+        admin.GET("/contracts", contractHandler.ListContracts)
+        admin.POST("/contracts", contractHandler.RegisterContract)
+        admin.DELETE("/contracts/:address", contractHandler.RemoveContract)
+    }
 
     return r
+}
+
+func (s *Server) healthHandler(c *gin.Context) {
+    dbHealth := s.db.Health()
+    listenerHealth := s.listener.Health()
+
+    status := http.StatusOK
+    if dbHealth["status"] != "healthy" || listenerHealth["status"] != "connected" {
+        status = http.StatusServiceUnavailable
+    }
+
+    c.JSON(status, gin.H{
+        "database": dbHealth,
+        "listener": listenerHealth,
+    })
+}
+
+// requireAdmin checks the bearer token on admin routes
+func (s *Server) requireAdmin(c *gin.Context) {
+    expected := "Bearer " + s.adminToken
+    if subtle.ConstantTimeCompare([]byte(c.GetHeader("Authorization")), []byte(expected)) != 1 {
+        c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+    }
 }
\ No newline at end of file
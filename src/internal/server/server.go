@@ -3,24 +3,40 @@ package server
 import (
     "fmt"
     "net/http"
-    "os"
-    "strconv"
     "time"
 
-    _ "github.com/joho/godotenv/autoload"
+    "src/internal/config"
     "src/internal/database"
+    "src/internal/services"
 )
 
+// Listener is the blockchain event listener as seen by the HTTP server
+type Listener interface {
+    Health() map[string]string
+    Reload()
+}
+
 type Server struct {
-    port int
-    db   database.Service
+    port       int
+    adminToken string
+    db         database.Service
+    listener   Listener
+    chain      services.ChainReader
+    pricing    config.PricingConfig
+
+    implementations map[string]string // Code hash to name of the compiled proxy implementations
 }
 
-func NewServer(db database.Service) *http.Server {
-    port, _ := strconv.Atoi(os.Getenv("PORT"))
+func NewServer(db database.Service, listener Listener, chain services.ChainReader, cfg config.ServerConfig, pricing config.PricingConfig, implementations map[string]string) *http.Server {
     newServer := &Server{
-        port: port,
-        db:   db,
+        port:       cfg.Port,
+        adminToken: cfg.AdminToken,
+        db:         db,
+        listener:   listener,
+        chain:      chain,
+        pricing:    pricing,
+
+        implementations: implementations,
     }
 
     server := &http.Server{
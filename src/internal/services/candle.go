@@ -0,0 +1,93 @@
+package services
+
+import (
+    "context"
+    "fmt"
+    "math/big"
+    "time"
+
+    "github.com/ethereum/go-ethereum/common"
+
+    "src/internal/database"
+    "src/internal/handlers"
+)
+
+// defaultCandles is how many candles are returned when no start time is given
+const defaultCandles = 500
+
+// GetCandles returns the pre-aggregated candles of a pool in [from, to).
+// A zero to means now and a zero from means defaultCandles intervals before to.
+func (s *PoolService) GetCandles(ctx context.Context, poolAddress, interval string, from, to time.Time) (*handlers.CandleResponse, error) {
+    if !common.IsHexAddress(poolAddress) {
+        return nil, fmt.Errorf("invalid pool address %q: %w", poolAddress, handlers.ErrInvalidInput)
+    }
+    poolAddress = common.HexToAddress(poolAddress).Hex()
+
+    size, ok := database.CandleIntervals[interval]
+    if !ok {
+        return nil, fmt.Errorf("invalid interval %q, expected 1m, 5m, 1h or 1d: %w", interval, handlers.ErrInvalidInput)
+    }
+
+    if to.IsZero() {
+        to = time.Now().UTC()
+    }
+    if from.IsZero() {
+        from = to.Add(-defaultCandles * size)
+    }
+    if !from.Before(to) {
+        return nil, fmt.Errorf("from must be before to: %w", handlers.ErrInvalidInput)
+    }
+
+    status := &handlers.PoolStatus{PoolAddress: poolAddress}
+    if err := s.resolveTokens(ctx, status); err != nil {
+        return nil, err
+    }
+    token0 := s.tokens.Get(ctx, status.Token0Address)
+    token1 := s.tokens.Get(ctx, status.Token1Address)
+
+    // Include the candle that was already open at from
+    candles, err := s.db.GetCandles(ctx, poolAddress, interval, from.Truncate(size), to)
+    if err != nil {
+        return nil, err
+    }
+
+    response := &handlers.CandleResponse{
+        PoolAddress:   poolAddress,
+        Token0Address: status.Token0Address,
+        Token1Address: status.Token1Address,
+        Interval:      interval,
+        From:          from,
+        To:            to,
+        Candles:       make([]*handlers.Candle, 0, len(candles)),
+    }
+
+    price := func(sqrtPriceX96 string) string {
+        value, ok := new(big.Int).SetString(sqrtPriceX96, 10)
+        if !ok {
+            return ""
+        }
+        return formatPrice(priceFromSqrtPriceX96(value, token0.Decimals, token1.Decimals))
+    }
+    amount := func(raw string, decimals uint8) string {
+        value, ok := new(big.Int).SetString(raw, 10)
+        if !ok {
+            return ""
+        }
+        return formatUnits(value, decimals)
+    }
+
+    for _, candle := range candles {
+        response.Candles = append(response.Candles, &handlers.Candle{
+            OpenTime: candle.OpenTime.UTC(),
+            Open:     price(candle.Open),
+            High:     price(candle.High),
+            Low:      price(candle.Low),
+            Close:    price(candle.Close),
+            Volume0:  amount(candle.Volume0, token0.Decimals),
+            Volume1:  amount(candle.Volume1, token1.Decimals),
+            Swaps:    candle.Swaps,
+        })
+    }
+
+    return response, nil
+}
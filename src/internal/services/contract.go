@@ -0,0 +1,106 @@
+package services
+
+import (
+    "context"
+    "fmt"
+
+    "github.com/ethereum/go-ethereum/common"
+
+    "src/internal/database"
+    "src/internal/handlers"
+)
+
+// ContractWatcher is notified when the set of watched contracts changes
+type ContractWatcher interface {
+    Reload()
+}
+
+type ContractService struct {
+    db      database.Service
+    watcher ContractWatcher
+}
+
+func NewContractService(db database.Service, watcher ContractWatcher) *ContractService {
+    return &ContractService{
+        db:      db,
+        watcher: watcher,
+    }
+}
+
+func (s *ContractService) ListContracts(ctx context.Context) ([]handlers.ContractInfo, error) {
+    contracts, err := s.db.GetContracts(ctx, false)
+    if err != nil {
+        return nil, err
+    }
+
+    infos := make([]handlers.ContractInfo, 0, len(contracts))
+    for _, contract := range contracts {
+        infos = append(infos, contractInfo(contract))
+    }
+    return infos, nil
+}
+
+func (s *ContractService) RegisterContract(ctx context.Context, req handlers.RegisterContractRequest) (*handlers.ContractInfo, error) {
+    if !common.IsHexAddress(req.Address) {
+        return nil, fmt.Errorf("%w: %q is not a valid address", handlers.ErrInvalidInput, req.Address)
+    }
+
+    switch req.Kind {
+    case database.ContractKindERC20, database.ContractKindV3Pool, database.ContractKindProxy, database.ContractKindFactory:
+    default:
+        return nil, fmt.Errorf("%w: kind must be one of: erc20, v3-pool, proxy, factory", handlers.ErrInvalidInput)
+    }
+
+    address := common.HexToAddress(req.Address).Hex()
+    contract, err := s.db.GetContract(ctx, address)
+    if err != nil {
+        return nil, err
+    }
+    if contract == nil || contract.Kind != req.Kind {
+        // Pool tokens are resolved by the listener when it picks the contract up
+        contract = &database.Contract{Address: address}
+    }
+
+    contract.Kind = req.Kind
+    contract.Name = req.Name
+    contract.DeploymentBlock = req.DeploymentBlock
+    contract.Active = true
+
+    if err := s.db.SaveContract(ctx, contract); err != nil {
+        return nil, err
+    }
+    s.watcher.Reload()
+
+    info := contractInfo(contract)
+    return &info, nil
+}
+
+func (s *ContractService) RemoveContract(ctx context.Context, address string) error {
+    if !common.IsHexAddress(address) {
+        return fmt.Errorf("%w: %q is not a valid address", handlers.ErrInvalidInput, address)
+    }
+
+    found, err := s.db.DeactivateContract(ctx, common.HexToAddress(address).Hex())
+    if err != nil {
+        return err
+    }
+    if !found {
+        return fmt.Errorf("contract %s: %w", address, handlers.ErrNotFound)
+    }
+
+    s.watcher.Reload()
+    return nil
+}
+
+func contractInfo(contract *database.Contract) handlers.ContractInfo {
+    return handlers.ContractInfo{
+        Address:         contract.Address,
+        Kind:            contract.Kind,
+        Name:            contract.Name,
+        Token0Address:   contract.Token0Address,
+        Token1Address:   contract.Token1Address,
+        DeploymentBlock: contract.DeploymentBlock,
+        Active:          contract.Active,
+        UpdatedAt:       contract.UpdatedAt,
+    }
+}
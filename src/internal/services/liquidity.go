@@ -0,0 +1,242 @@
+package services
+
+import (
+    "context"
+    "fmt"
+    "math"
+    "math/big"
+    "sort"
+
+    "github.com/ethereum/go-ethereum/common"
+
+    "src/internal/database"
+    "src/internal/handlers"
+)
+
+// liquidityState is a pool's tick liquidity rebuilt from its indexed Mint and
+// Burn events, positioned at the current price
+type liquidityState struct {
+    sqrtPriceX96 *big.Int
+    tick         int
+    liquidity    *big.Int // Active at the current tick
+    ticks        []int    // Initialized ticks, ascending
+    net          map[int]*big.Int
+    gross        map[int]*big.Int
+    price        *poolPrice
+}
+
+// liquidityState rebuilds a pool's liquidity from its indexed position events
+// and positions it at the current price
+func (s *PoolService) liquidityState(ctx context.Context, poolAddress, token0, token1 string) (*liquidityState, error) {
+    events, err := s.db.GetPositionEvents(ctx, poolAddress)
+    if err != nil {
+        return nil, err
+    }
+
+    latestSwap, err := s.db.GetLatestSwap(ctx, poolAddress)
+    if err != nil {
+        return nil, err
+    }
+    price, err := s.currentPrice(ctx, poolAddress, token0, token1, latestSwap)
+    if err != nil {
+        return nil, err
+    }
+
+    state := newLiquidityState(events, price.sqrtPriceX96, price.tick)
+    state.price = price
+    return state, nil
+}
+
+// newLiquidityState replays Mint and Burn events in log order. A position adds
+// its liquidity when the price crosses its lower tick upwards and removes it at
+// its upper tick, so liquidityNet is +L at tickLower and -L at tickUpper.
+func newLiquidityState(events []*database.PoolTransaction, sqrtPriceX96 *big.Int, tick int) *liquidityState {
+    state := &liquidityState{
+        sqrtPriceX96: sqrtPriceX96,
+        tick:         tick,
+        liquidity:    new(big.Int),
+        net:          make(map[int]*big.Int),
+        gross:        make(map[int]*big.Int),
+    }
+    add := func(m map[int]*big.Int, tick int, delta *big.Int) {
+        if m[tick] == nil {
+            m[tick] = new(big.Int)
+        }
+        m[tick].Add(m[tick], delta)
+    }
+
+    for _, event := range events {
+        amount, ok := new(big.Int).SetString(event.Liquidity, 10)
+        if !ok || amount.Sign() == 0 {
+            continue
+        }
+        if event.EventType == "Burn" {
+            amount.Neg(amount)
+        }
+
+        add(state.net, event.TickLower, amount)
+        add(state.net, event.TickUpper, new(big.Int).Neg(amount))
+        add(state.gross, event.TickLower, amount)
+        add(state.gross, event.TickUpper, amount)
+    }
+
+    for tick, gross := range state.gross {
+        if gross.Sign() <= 0 {
+            delete(state.gross, tick)
+            delete(state.net, tick)
+            continue
+        }
+        state.ticks = append(state.ticks, tick)
+    }
+    sort.Ints(state.ticks)
+
+    for _, tick := range state.ticks {
+        if tick > state.tick {
+            break
+        }
+        state.liquidity.Add(state.liquidity, state.net[tick])
+    }
+
+    return state
+}
+
+// depth returns the token0 that can be bought before the price rises by
+// percent and the token1 that can be bought before it falls by percent
+func (st *liquidityState) depth(percent float64) (*big.Int, *big.Int) {
+    upperTick := st.tick + int(math.Log(1+percent/100)/math.Log(1.0001))
+    lowerTick := st.tick + int(math.Log(1-percent/100)/math.Log(1.0001))
+    upperTarget := sqrtRatioAtTick(min(upperTick, maxTick))
+    lowerTarget := sqrtRatioAtTick(max(lowerTick, minTick))
+
+    // Walk up through the ticks above the price, selling token0
+    amount0 := new(big.Int)
+    liquidity := new(big.Int).Set(st.liquidity)
+    sqrtPrice := st.sqrtPriceX96
+    for _, tick := range st.ticks {
+        if tick <= st.tick {
+            continue
+        }
+        next := sqrtRatioAtTick(tick)
+        if next.Cmp(upperTarget) >= 0 {
+            break
+        }
+        amount0.Add(amount0, amount0Delta(sqrtPrice, next, liquidity))
+        sqrtPrice = next
+        liquidity.Add(liquidity, st.net[tick])
+    }
+    if upperTarget.Cmp(sqrtPrice) > 0 {
+        amount0.Add(amount0, amount0Delta(sqrtPrice, upperTarget, liquidity))
+    }
+
+    // Walk down through the ticks at or below the price, selling token1
+    amount1 := new(big.Int)
+    liquidity.Set(st.liquidity)
+    sqrtPrice = st.sqrtPriceX96
+    for i := len(st.ticks) - 1; i >= 0; i-- {
+        tick := st.ticks[i]
+        if tick > st.tick {
+            continue
+        }
+        next := sqrtRatioAtTick(tick)
+        if next.Cmp(lowerTarget) <= 0 {
+            break
+        }
+        amount1.Add(amount1, amount1Delta(next, sqrtPrice, liquidity))
+        sqrtPrice = next
+        liquidity.Sub(liquidity, st.net[tick])
+    }
+    if lowerTarget.Cmp(sqrtPrice) < 0 {
+        amount1.Add(amount1, amount1Delta(lowerTarget, sqrtPrice, liquidity))
+    }
+
+    return amount0, amount1
+}
+
+// amount0Delta is the token0 held by liquidity between two sqrt prices,
+// L * (b - a) / (a * b) with the prices in Q64.96, rounded down
+func amount0Delta(sqrtA, sqrtB, liquidity *big.Int) *big.Int {
+    if sqrtA.Cmp(sqrtB) > 0 {
+        sqrtA, sqrtB = sqrtB, sqrtA
+    }
+    if sqrtA.Sign() == 0 {
+        return new(big.Int)
+    }
+    num := new(big.Int).Lsh(liquidity, 96)
+    num.Mul(num, new(big.Int).Sub(sqrtB, sqrtA))
+    num.Quo(num, sqrtB)
+    return num.Quo(num, sqrtA)
+}
+
+// amount1Delta is the token1 held by liquidity between two sqrt prices,
+// L * (b - a) with the prices in Q64.96, rounded down
+func amount1Delta(sqrtA, sqrtB, liquidity *big.Int) *big.Int {
+    if sqrtA.Cmp(sqrtB) > 0 {
+        sqrtA, sqrtB = sqrtB, sqrtA
+    }
+    amount := new(big.Int).Mul(liquidity, new(big.Int).Sub(sqrtB, sqrtA))
+    return amount.Rsh(amount, 96)
+}
+
+// GetLiquidity returns the pool's liquidity curve and its depth within each
+// of the given percentages of the current price
+func (s *PoolService) GetLiquidity(ctx context.Context, poolAddress string, depths []float64) (*handlers.LiquidityDistribution, error) {
+    if !common.IsHexAddress(poolAddress) {
+        return nil, fmt.Errorf("invalid pool address %q: %w", poolAddress, handlers.ErrInvalidInput)
+    }
+    poolAddress = common.HexToAddress(poolAddress).Hex()
+
+    for _, percent := range depths {
+        if percent <= 0 || percent >= 100 {
+            return nil, fmt.Errorf("depth %v must be between 0 and 100 percent: %w", percent, handlers.ErrInvalidInput)
+        }
+    }
+
+    token0Address, token1Address, err := poolTokens(ctx, s.db, poolAddress)
+    if err != nil {
+        return nil, err
+    }
+    state, err := s.liquidityState(ctx, poolAddress, token0Address, token1Address)
+    if err != nil {
+        return nil, err
+    }
+
+    token0 := s.tokens.Get(ctx, token0Address)
+    token1 := s.tokens.Get(ctx, token1Address)
+
+    distribution := &handlers.LiquidityDistribution{
+        PoolAddress:     poolAddress,
+        Token0Address:   token0Address,
+        Token1Address:   token1Address,
+        CurrentTick:     state.tick,
+        SqrtPriceX96:    state.sqrtPriceX96.String(),
+        Price:           formatPrice(state.price.price),
+        PriceSource:     state.price.source,
+        ActiveLiquidity: state.liquidity.String(),
+        Ticks:           make([]handlers.LiquidityTick, 0, len(state.ticks)),
+    }
+
+    liquidity := new(big.Int)
+    for _, tick := range state.ticks {
+        liquidity.Add(liquidity, state.net[tick])
+        distribution.Ticks = append(distribution.Ticks, handlers.LiquidityTick{
+            Tick:           tick,
+            Price:          formatPrice(priceAtTick(tick, token0.Decimals, token1.Decimals)),
+            LiquidityNet:   state.net[tick].String(),
+            LiquidityGross: state.gross[tick].String(),
+            Liquidity:      liquidity.String(),
+        })
+    }
+
+    for _, percent := range depths {
+        amount0, amount1 := state.depth(percent)
+        distribution.Depth = append(distribution.Depth, handlers.LiquidityDepth{
+            Percent:   percent,
+            Token0:    formatUnits(amount0, token0.Decimals),
+            Token1:    formatUnits(amount1, token1.Decimals),
+            Token0Raw: amount0.String(),
+            Token1Raw: amount1.String(),
+        })
+    }
+
+    return distribution, nil
+}
@@ -2,25 +2,51 @@ package services
 
 import (
     "context"
+    "fmt"
+    "log"
     "math/big"
     "time"
+
+    "github.com/ethereum/go-ethereum/common"
+
+    "src/internal/config"
     "src/internal/database"
     "src/internal/handlers"
 )
 
 type PoolService struct {
-    db database.Service
+    db            database.Service
+    tokens        *TokenRegistry
+    chain         ChainReader
+    quoteToken    string // Optional, checksummed
+    windows       []volumeWindow
+    quoterAddress string // Optional QuoterV2 used to verify quotes
 }
 
-func NewPoolService(db database.Service) *PoolService {
+func NewPoolService(db database.Service, tokens *TokenRegistry, chain ChainReader, cfg config.PricingConfig) *PoolService {
+    quoteToken := cfg.QuoteToken
+    if quoteToken != "" {
+        quoteToken = common.HexToAddress(quoteToken).Hex()
+    }
+
     return &PoolService{
-        db: db,
+        db:            db,
+        tokens:        tokens,
+        chain:         chain,
+        quoteToken:    quoteToken,
+        windows:       parseVolumeWindows(cfg.VolumeWindows),
+        quoterAddress: cfg.QuoterAddress,
     }
 }
 
-func (s *PoolService) GetPoolStatus(ctx context.Context, poolAddress string) (*handlers.PoolStatus, error) {
-    // Get all pool transactions for the last 24 hours
-    transactions, err := s.db.GetPoolTransactions(ctx, poolAddress)
+func (s *PoolService) GetPoolStatus(ctx context.Context, poolAddress string, finality string) (*handlers.PoolStatus, error) {
+    // Events are stored with checksummed addresses
+    if common.IsHexAddress(poolAddress) {
+        poolAddress = common.HexToAddress(poolAddress).Hex()
+    }
+
+    // Get all pool transactions, ordered by block
+    transactions, err := s.db.GetPoolTransactions(ctx, poolAddress, finality == handlers.FinalityConfirmed)
     if err != nil {
         return nil, err
     }
@@ -28,13 +54,11 @@ func (s *PoolService) GetPoolStatus(ctx context.Context, poolAddress string) (*h
     status := &handlers.PoolStatus{
         PoolAddress:  poolAddress,
         LastUpdated: time.Now(),
+        Finality:    finality,
     }
 
     // Calculate basic metrics
-    volume24h := new(big.Int)
-    now := time.Now()
-    twentyFourHoursAgo := now.Add(-24 * time.Hour)
-
+    var latestSwap *database.PoolTransaction
     for _, tx := range transactions {
         // Set token addresses from the first transaction
         if status.Token0Address == "" {
@@ -46,12 +70,7 @@ func (s *PoolService) GetPoolStatus(ctx context.Context, poolAddress string) (*h
         switch tx.EventType {
         case "Swap":
             status.TotalSwaps++
-            // Add to 24h volume if within time window
-            if tx.Timestamp.After(twentyFourHoursAgo) {
-                amount0 := new(big.Int)
-                amount0.SetString(tx.Amount0, 10)
-                volume24h.Add(volume24h, amount0)
-            }
+            latestSwap = tx
         case "Mint":
             status.TotalMints++
         case "Burn":
@@ -59,14 +78,180 @@ func (s *PoolService) GetPoolStatus(ctx context.Context, poolAddress string) (*h
         }
     }
 
-    // Convert volume to string
-    status.Volume24h = volume24h.String()
+    if status.Token0Address == "" {
+        if err := s.resolveTokens(ctx, status); err != nil {
+            return nil, err
+        }
+    }
+
+    price, err := s.setPrice(ctx, status, latestSwap)
+    if err != nil {
+        log.Printf("Failed to price pool %s: %v", poolAddress, err)
+    }
+
+    reserve0, reserve1, err := s.reserves(ctx, poolAddress, transactions, finality)
+    if err != nil {
+        return nil, err
+    }
+    if finality != handlers.FinalityConfirmed {
+        status.ReservesVerified = s.verifyReserves(ctx, status, reserve0, reserve1)
+    }
 
-    // Set a placeholder current price (you'll need to implement actual price calculation)
-    status.CurrentPrice = "1.0"
+    valuer := s.newValuer(ctx, status, price)
+    s.setTVL(ctx, status, reserve0, reserve1, valuer)
 
-    // Set a placeholder TVL (you'll need to implement actual TVL calculation)
-    status.TVL = "0"
+    fee, err := s.feeTier(ctx, poolAddress)
+    if err != nil {
+        log.Printf("Failed to get fee tier of pool %s: %v", poolAddress, err)
+    }
+    status.FeeTier = fee
+    s.setVolume(ctx, status, transactions, fee, valuer)
 
     return status, nil
-}
\ No newline at end of file
+}
+
+// resolveTokens fills in the pool's tokens from the contract registry when no
+// events have been indexed yet
+func (s *PoolService) resolveTokens(ctx context.Context, status *handlers.PoolStatus) error {
+    token0, token1, err := poolTokens(ctx, s.db, status.PoolAddress)
+    if err != nil {
+        return err
+    }
+
+    status.Token0Address = token0
+    status.Token1Address = token1
+    return nil
+}
+
+// poolTokens looks up a pool's tokens in the contract registry
+func poolTokens(ctx context.Context, db database.Service, poolAddress string) (string, string, error) {
+    contract, err := db.GetContract(ctx, poolAddress)
+    if err != nil {
+        return "", "", err
+    }
+    if contract == nil || contract.Kind != database.ContractKindV3Pool {
+        return "", "", fmt.Errorf("pool %s: %w", poolAddress, handlers.ErrNotFound)
+    }
+    return contract.Token0Address, contract.Token1Address, nil
+}
+
+// poolPrice is the current price of a pool and where it was read from
+type poolPrice struct {
+    price        *big.Rat // token1 per token0, adjusted for decimals
+    sqrtPriceX96 *big.Int
+    tick         int
+    source       string // "swap" or "slot0"
+}
+
+// currentPrice derives the pool price from the latest indexed swap, falling
+// back to the pool's slot0 when no swap has been indexed
+func (s *PoolService) currentPrice(ctx context.Context, poolAddress, token0Address, token1Address string, latestSwap *database.PoolTransaction) (*poolPrice, error) {
+    current := &poolPrice{sqrtPriceX96: new(big.Int)}
+    if latestSwap != nil && latestSwap.SqrtPriceX96 != "" {
+        current.sqrtPriceX96.SetString(latestSwap.SqrtPriceX96, 10)
+        current.tick = latestSwap.Tick
+        current.source = "swap"
+    } else {
+        sqrtPriceX96, tick, err := s.chain.Slot0(ctx, poolAddress)
+        if err != nil {
+            return nil, err
+        }
+        current.sqrtPriceX96 = sqrtPriceX96
+        current.tick = tick
+        current.source = "slot0"
+    }
+
+    token0 := s.tokens.Get(ctx, token0Address)
+    token1 := s.tokens.Get(ctx, token1Address)
+    current.price = priceFromSqrtPriceX96(current.sqrtPriceX96, token0.Decimals, token1.Decimals)
+    return current, nil
+}
+
+// setPrice fills in the pool's price fields and returns the price of token0 in token1
+func (s *PoolService) setPrice(ctx context.Context, status *handlers.PoolStatus, latestSwap *database.PoolTransaction) (*big.Rat, error) {
+    current, err := s.currentPrice(ctx, status.PoolAddress, status.Token0Address, status.Token1Address, latestSwap)
+    if err != nil {
+        return nil, err
+    }
+
+    status.SqrtPriceX96 = current.sqrtPriceX96.String()
+    status.Tick = current.tick
+    status.PriceSource = current.source
+    status.Token0Price = formatPrice(current.price)
+    status.Token1Price = formatPrice(invertPrice(current.price))
+    status.CurrentPrice = status.Token0Price
+    return current.price, nil
+}
+
+// reserves returns the pool's token balances. Latest reads use the running
+// reserves, confirmed reads (and pools indexed before reserves were tracked)
+// sum the given events.
+func (s *PoolService) reserves(ctx context.Context, poolAddress string, transactions []*database.PoolTransaction, finality string) (*big.Int, *big.Int, error) {
+    if finality != handlers.FinalityConfirmed {
+        stored, err := s.db.GetPoolReserves(ctx, poolAddress)
+        if err != nil {
+            return nil, nil, err
+        }
+        if stored != nil {
+            reserve0, _ := new(big.Int).SetString(stored.Reserve0, 10)
+            reserve1, _ := new(big.Int).SetString(stored.Reserve1, 10)
+            if reserve0 != nil && reserve1 != nil {
+                return reserve0, reserve1, nil
+            }
+        }
+    }
+
+    reserve0, reserve1 := new(big.Int), new(big.Int)
+    for _, tx := range transactions {
+        delta0, delta1 := database.ReserveDeltas(tx)
+        reserve0.Add(reserve0, delta0)
+        reserve1.Add(reserve1, delta1)
+    }
+    return reserve0, reserve1, nil
+}
+
+// verifyReserves compares the indexed reserves with the pool's on-chain token balances
+func (s *PoolService) verifyReserves(ctx context.Context, status *handlers.PoolStatus, reserve0, reserve1 *big.Int) bool {
+    balance0, err := s.chain.BalanceOf(ctx, status.Token0Address, status.PoolAddress)
+    if err != nil {
+        log.Printf("Failed to verify reserves of pool %s: %v", status.PoolAddress, err)
+        return false
+    }
+    balance1, err := s.chain.BalanceOf(ctx, status.Token1Address, status.PoolAddress)
+    if err != nil {
+        log.Printf("Failed to verify reserves of pool %s: %v", status.PoolAddress, err)
+        return false
+    }
+
+    if balance0.Cmp(reserve0) != 0 || balance1.Cmp(reserve1) != 0 {
+        log.Printf("Pool %s reserves differ from its balances: indexed %s/%s, on-chain %s/%s",
+            status.PoolAddress, reserve0, reserve1, balance0, balance1)
+        return false
+    }
+    return true
+}
+
+// setTVL fills in the pool's reserves and their value in each token and in
+// the valuation unit
+func (s *PoolService) setTVL(ctx context.Context, status *handlers.PoolStatus, reserve0, reserve1 *big.Int, valuer *poolValuer) {
+    token0 := s.tokens.Get(ctx, status.Token0Address)
+    token1 := s.tokens.Get(ctx, status.Token1Address)
+
+    status.Reserve0Raw = reserve0.String()
+    status.Reserve1Raw = reserve1.String()
+    status.Reserve0 = formatUnits(reserve0, token0.Decimals)
+    status.Reserve1 = formatUnits(reserve1, token1.Decimals)
+
+    if valuer == nil {
+        return
+    }
+
+    amount0 := new(big.Rat).SetFrac(reserve0, pow10(token0.Decimals))
+    amount1 := new(big.Rat).SetFrac(reserve1, pow10(token1.Decimals))
+
+    tvl1 := valuer.inToken1(amount0, amount1)
+    status.TVLToken0 = formatPrice(new(big.Rat).Mul(tvl1, invertPrice(valuer.price)))
+    status.TVLToken1 = formatPrice(tvl1)
+    status.TVL = formatPrice(valuer.value(amount0, amount1))
+    status.TVLUnit = valuer.unit
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+    "math/big"
+)
+
+// pricePrecision is the number of decimal places prices are reported with
+const pricePrecision = 18
+
+// q192 is 2^192, the scale of a squared Q64.96 sqrt price
+var q192 = new(big.Int).Lsh(big.NewInt(1), 192)
+
+// priceFromSqrtPriceX96 returns the exact price of token0 in token1 units,
+// (sqrtPriceX96 / 2^96)^2 * 10^(decimals0 - decimals1)
+func priceFromSqrtPriceX96(sqrtPriceX96 *big.Int, decimals0, decimals1 uint8) *big.Rat {
+    num := new(big.Int).Mul(sqrtPriceX96, sqrtPriceX96)
+    num.Mul(num, pow10(decimals0))
+    den := new(big.Int).Mul(q192, pow10(decimals1))
+    return new(big.Rat).SetFrac(num, den)
+}
+
+// invertPrice returns 1/price, or zero for a zero price
+func invertPrice(price *big.Rat) *big.Rat {
+    if price.Sign() == 0 {
+        return new(big.Rat)
+    }
+    return new(big.Rat).Inv(price)
+}
+
+func formatPrice(price *big.Rat) string {
+    return price.FloatString(pricePrecision)
+}
+
+func pow10(exp uint8) *big.Int {
+    return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil)
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+    "context"
+    "fmt"
+    "math/big"
+
+    "github.com/ethereum/go-ethereum/common"
+
+    "src/internal/database"
+    "src/internal/handlers"
+)
+
+// ProxyService reports the implementation history of registered proxies and
+// inspects what code a proxy currently delegates to
+type ProxyService struct {
+    db              database.Service
+    tokens          *TokenRegistry
+    chain           ChainReader
+    implementations map[string]string // Runtime code hash to contract name of the compiled implementations
+}
+
+func NewProxyService(db database.Service, tokens *TokenRegistry, chain ChainReader, implementations map[string]string) *ProxyService {
+    return &ProxyService{
+        db:              db,
+        tokens:          tokens,
+        chain:           chain,
+        implementations: implementations,
+    }
+}
+
+// GetProxyHistory replays a proxy's indexed events in order, so the current
+// implementation, admin and beacon are whatever the last events set
+func (s *ProxyService) GetProxyHistory(ctx context.Context, proxyAddress string) (*handlers.ProxyHistory, error) {
+    if !common.IsHexAddress(proxyAddress) {
+        return nil, fmt.Errorf("invalid proxy address %q: %w", proxyAddress, handlers.ErrInvalidInput)
+    }
+    proxyAddress = common.HexToAddress(proxyAddress).Hex()
+
+    contract, err := s.db.GetContract(ctx, proxyAddress)
+    if err != nil {
+        return nil, err
+    }
+    if contract == nil || contract.Kind != database.ContractKindProxy {
+        return nil, fmt.Errorf("%s is not a registered proxy: %w", proxyAddress, handlers.ErrNotFound)
+    }
+
+    events, err := s.db.GetProxyEvents(ctx, proxyAddress)
+    if err != nil {
+        return nil, err
+    }
+
+    history := &handlers.ProxyHistory{
+        ProxyAddress: proxyAddress,
+        Events:       make([]handlers.ProxyUpgrade, 0, len(events)),
+    }
+    for _, event := range events {
+        switch event.EventType {
+        case "Upgraded":
+            history.Implementation = event.Implementation
+            history.Version = event.Version
+            history.Upgrades++
+        case "AdminChanged":
+            history.Admin = event.NewAdmin
+        case "BeaconUpgraded":
+            history.Beacon = event.Beacon
+        }
+
+        history.Events = append(history.Events, handlers.ProxyUpgrade{
+            EventType:      event.EventType,
+            Implementation: event.Implementation,
+            Version:        event.Version,
+            PreviousAdmin:  event.PreviousAdmin,
+            NewAdmin:       event.NewAdmin,
+            Beacon:         event.Beacon,
+            BlockNumber:    event.BlockNumber,
+            TxHash:         event.TxHash,
+            LogIndex:       event.LogIndex,
+            Timestamp:      event.Timestamp,
+            Status:         event.Status,
+        })
+    }
+
+    return history, nil
+}
+
+// InspectProxy reads the proxy's EIP-1967 slots directly from the chain and
+// identifies the implementation by its code hash, without relying on events
+func (s *ProxyService) InspectProxy(ctx context.Context, proxyAddress string) (*handlers.ProxyInspection, error) {
+    if !common.IsHexAddress(proxyAddress) {
+        return nil, fmt.Errorf("invalid proxy address %q: %w", proxyAddress, handlers.ErrInvalidInput)
+    }
+    proxyAddress = common.HexToAddress(proxyAddress).Hex()
+
+    implementation, admin, beacon, err := s.chain.ProxySlots(ctx, proxyAddress)
+    if err != nil {
+        return nil, err
+    }
+    zero := common.Address{}.Hex()
+    if implementation == zero && beacon == zero {
+        return nil, fmt.Errorf("%s has no EIP-1967 implementation or beacon: %w", proxyAddress, handlers.ErrNotFound)
+    }
+
+    inspection := &handlers.ProxyInspection{
+        ProxyAddress: proxyAddress,
+    }
+    if admin != zero {
+        inspection.Admin = admin
+    }
+    if beacon != zero {
+        // The implementation lives in the beacon, which is not inspected further
+        inspection.Beacon = beacon
+        inspection.Warnings = append(inspection.Warnings, "proxy uses a beacon; the implementation slot is not used")
+    }
+
+    if implementation != zero {
+        inspection.Implementation = implementation
+
+        inspection.CodeHash, err = s.chain.CodeHash(ctx, implementation)
+        if err != nil {
+            return nil, err
+        }
+        if inspection.CodeHash == (common.Hash{}).Hex() {
+            inspection.Warnings = append(inspection.Warnings, "implementation has no code")
+        }
+
+        if version, err := s.chain.Version(ctx, implementation); err == nil {
+            inspection.Version = version
+        }
+
+        inspection.Contract, inspection.Known = s.implementations[inspection.CodeHash]
+        if !inspection.Known {
+            if len(s.implementations) == 0 {
+                inspection.Warnings = append(inspection.Warnings, "no compiled artifacts loaded to compare against")
+            } else {
+                inspection.Warnings = append(inspection.Warnings, "implementation code does not match any compiled artifact")
+            }
+        }
+    }
+
+    // Compare with the implementation the indexed Upgraded events point at
+    events, err := s.db.GetProxyEvents(ctx, proxyAddress)
+    if err != nil {
+        return nil, err
+    }
+    for _, event := range events {
+        if event.EventType == "Upgraded" {
+            inspection.IndexedImplementation = event.Implementation
+        }
+    }
+    if inspection.IndexedImplementation != "" && inspection.Implementation != "" {
+        matches := inspection.IndexedImplementation == inspection.Implementation
+        inspection.MatchesIndexed = &matches
+        if !matches {
+            inspection.Warnings = append(inspection.Warnings, "implementation slot differs from the last indexed Upgraded event")
+        }
+    }
+
+    return inspection, nil
+}
+
+// GetUserSwaps returns the swaps user made through registered proxies
+func (s *ProxyService) GetUserSwaps(ctx context.Context, user, proxyAddress, finality string) (*handlers.ProxySwapHistory, error) {
+    if !common.IsHexAddress(user) {
+        return nil, fmt.Errorf("invalid user address %q: %w", user, handlers.ErrInvalidInput)
+    }
+    user = common.HexToAddress(user).Hex()
+    if proxyAddress != "" {
+        if !common.IsHexAddress(proxyAddress) {
+            return nil, fmt.Errorf("invalid proxy address %q: %w", proxyAddress, handlers.ErrInvalidInput)
+        }
+        proxyAddress = common.HexToAddress(proxyAddress).Hex()
+    }
+
+    swaps, err := s.db.GetProxySwapsByUser(ctx, user, proxyAddress, finality == handlers.FinalityConfirmed)
+    if err != nil {
+        return nil, err
+    }
+
+    history := &handlers.ProxySwapHistory{
+        User:     user,
+        Count:    len(swaps),
+        Swaps:    make([]handlers.ProxySwap, 0, len(swaps)),
+        Finality: finality,
+    }
+    for _, swap := range swaps {
+        tokenIn := s.tokens.Get(ctx, swap.TokenIn)
+        var decimalsOut uint8 = defaultDecimals
+        if swap.TokenOut != "" {
+            decimalsOut = s.tokens.Get(ctx, swap.TokenOut).Decimals
+        }
+
+        history.Swaps = append(history.Swaps, handlers.ProxySwap{
+            ProxyAddress:          swap.ProxyAddress,
+            Mode:                  swap.Mode,
+            TokenIn:               swap.TokenIn,
+            TokenOut:              swap.TokenOut,
+            PoolAddress:           swap.PoolAddress,
+            AmountIn:              formatUnits(parseAmount(swap.AmountIn), tokenIn.Decimals),
+            AmountInRaw:           swap.AmountIn,
+            AmountOut:             formatUnits(parseAmount(swap.AmountOut), decimalsOut),
+            AmountOutRaw:          swap.AmountOut,
+            AmountInMaximumRaw:    swap.AmountInMaximum,
+            AmountOutRequestedRaw: swap.AmountOutRequested,
+            Refund:                formatUnits(parseAmount(swap.Refund), tokenIn.Decimals),
+            RefundRaw:             swap.Refund,
+            Implementation:        swap.Implementation,
+            Version:               swap.Version,
+            TxHash:                swap.TxHash,
+            BlockNumber:           swap.BlockNumber,
+            Timestamp:             swap.Timestamp,
+            Status:                swap.Status,
+        })
+    }
+
+    return history, nil
+}
+
+// parseAmount reads a stored base unit amount, treating a malformed one as zero
+func parseAmount(value string) *big.Int {
+    amount, ok := new(big.Int).SetString(value, 10)
+    if !ok {
+        return new(big.Int)
+    }
+    return amount
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+    "context"
+    "log"
+    "math/big"
+
+    "src/internal/database"
+    "src/internal/handlers"
+)
+
+// priceEdge converts one unit of a token into rate units of another through a pool
+type priceEdge struct {
+    token string
+    rate  *big.Rat
+}
+
+// priceGraph links tokens through the current prices of the registered pools
+type priceGraph map[string][]priceEdge
+
+// priceGraph prices every active pool in the registry. Pools that cannot be
+// priced are left out of the graph.
+func (s *PoolService) priceGraph(ctx context.Context) (priceGraph, error) {
+    contracts, err := s.db.GetContracts(ctx, true)
+    if err != nil {
+        return nil, err
+    }
+
+    graph := make(priceGraph)
+    for _, contract := range contracts {
+        if contract.Kind != database.ContractKindV3Pool || contract.Token0Address == "" {
+            continue
+        }
+
+        latestSwap, err := s.db.GetLatestSwap(ctx, contract.Address)
+        if err != nil {
+            log.Printf("Failed to price pool %s: %v", contract.Address, err)
+            continue
+        }
+        price, err := s.currentPrice(ctx, contract.Address, contract.Token0Address, contract.Token1Address, latestSwap)
+        if err != nil {
+            log.Printf("Failed to price pool %s: %v", contract.Address, err)
+            continue
+        }
+        if price.price.Sign() == 0 {
+            continue
+        }
+
+        graph[contract.Token0Address] = append(graph[contract.Token0Address], priceEdge{token: contract.Token1Address, rate: price.price})
+        graph[contract.Token1Address] = append(graph[contract.Token1Address], priceEdge{token: contract.Token0Address, rate: invertPrice(price.price)})
+    }
+
+    return graph, nil
+}
+
+// convert expresses amount of token from in units of token to along the path
+// with the fewest pools, reporting false when the tokens are not connected
+func (g priceGraph) convert(amount *big.Rat, from, to string) (*big.Rat, bool) {
+    if from == to {
+        return new(big.Rat).Set(amount), true
+    }
+
+    rates := map[string]*big.Rat{from: big.NewRat(1, 1)}
+    queue := []string{from}
+    for len(queue) > 0 {
+        token := queue[0]
+        queue = queue[1:]
+
+        for _, edge := range g[token] {
+            if _, seen := rates[edge.token]; seen {
+                continue
+            }
+            rates[edge.token] = new(big.Rat).Mul(rates[token], edge.rate)
+            if edge.token == to {
+                return new(big.Rat).Mul(amount, rates[to]), true
+            }
+            queue = append(queue, edge.token)
+        }
+    }
+
+    return nil, false
+}
+
+// poolValuer values amounts of a pool's tokens in a single unit: the quote
+// token when one is configured and reachable through the price graph, token1
+// otherwise
+type poolValuer struct {
+    price *big.Rat // token1 per token0
+    rate  *big.Rat // unit per token1
+    unit  string
+}
+
+// newValuer returns nil when the pool could not be priced
+func (s *PoolService) newValuer(ctx context.Context, status *handlers.PoolStatus, price *big.Rat) *poolValuer {
+    if price == nil || price.Sign() == 0 {
+        return nil
+    }
+
+    valuer := &poolValuer{
+        price: price,
+        rate:  big.NewRat(1, 1),
+        unit:  status.Token1Address,
+    }
+    if s.quoteToken == "" {
+        return valuer
+    }
+
+    graph, err := s.priceGraph(ctx)
+    if err != nil {
+        log.Printf("Failed to build price graph: %v", err)
+        return valuer
+    }
+    if rate, ok := graph.convert(big.NewRat(1, 1), status.Token1Address, s.quoteToken); ok {
+        valuer.rate = rate
+        valuer.unit = s.quoteToken
+    }
+    return valuer
+}
+
+// inToken1 returns the combined value of token amounts in token1
+func (v *poolValuer) inToken1(amount0, amount1 *big.Rat) *big.Rat {
+    return new(big.Rat).Add(new(big.Rat).Mul(amount0, v.price), amount1)
+}
+
+// value returns the combined value of token amounts in the valuation unit
+func (v *poolValuer) value(amount0, amount1 *big.Rat) *big.Rat {
+    return new(big.Rat).Mul(v.inToken1(amount0, amount1), v.rate)
+}
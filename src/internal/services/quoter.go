@@ -0,0 +1,299 @@
+package services
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "math/big"
+    "sort"
+
+    "github.com/ethereum/go-ethereum/common"
+
+    "src/internal/handlers"
+)
+
+// swapResult is the outcome of a simulated swap
+type swapResult struct {
+    amountIn          *big.Int // Including the fee
+    amountOut         *big.Int
+    feeAmount         *big.Int
+    sqrtPriceX96After *big.Int
+    tickAfter         int
+    ticksCrossed      int
+    partial           bool // The pool ran out of liquidity before the amount was filled
+}
+
+// simulateSwap replays UniswapV3Pool.swap against the rebuilt liquidity
+// state. amountSpecified is positive for exact input and negative for exact
+// output, and no price limit is applied.
+func (st *liquidityState) simulateSwap(zeroForOne bool, amountSpecified *big.Int, fee uint32, tickSpacing int) *swapResult {
+    exactInput := amountSpecified.Sign() > 0
+
+    limit := new(big.Int).Add(minSqrtRatio, big.NewInt(1))
+    if !zeroForOne {
+        limit = new(big.Int).Sub(maxSqrtRatio, big.NewInt(1))
+    }
+
+    remaining := new(big.Int).Set(amountSpecified)
+    sqrtPrice := new(big.Int).Set(st.sqrtPriceX96)
+    tick := st.tick
+    liquidity := new(big.Int).Set(st.liquidity)
+    result := &swapResult{
+        amountIn:  new(big.Int),
+        amountOut: new(big.Int),
+        feeAmount: new(big.Int),
+    }
+
+    for remaining.Sign() != 0 && sqrtPrice.Cmp(limit) != 0 {
+        start := new(big.Int).Set(sqrtPrice)
+
+        tickNext, initialized := st.nextInitializedTick(tick, tickSpacing, zeroForOne)
+        tickNext = max(min(tickNext, maxTick), minTick)
+        sqrtPriceNext := sqrtRatioAtTick(tickNext)
+
+        target := sqrtPriceNext
+        if (zeroForOne && sqrtPriceNext.Cmp(limit) < 0) || (!zeroForOne && sqrtPriceNext.Cmp(limit) > 0) {
+            target = limit
+        }
+
+        step := computeSwapStep(sqrtPrice, target, liquidity, remaining, fee)
+        if step == nil {
+            result.partial = true
+            break
+        }
+        sqrtPrice = step.sqrtPriceNext
+
+        if exactInput {
+            remaining.Sub(remaining, step.amountIn)
+            remaining.Sub(remaining, step.feeAmount)
+        } else {
+            remaining.Add(remaining, step.amountOut)
+        }
+        result.amountIn.Add(result.amountIn, step.amountIn)
+        result.amountIn.Add(result.amountIn, step.feeAmount)
+        result.amountOut.Add(result.amountOut, step.amountOut)
+        result.feeAmount.Add(result.feeAmount, step.feeAmount)
+
+        if sqrtPrice.Cmp(sqrtPriceNext) == 0 {
+            if initialized {
+                liquidityNet := new(big.Int).Set(st.net[tickNext])
+                if zeroForOne {
+                    liquidityNet.Neg(liquidityNet)
+                }
+                liquidity.Add(liquidity, liquidityNet)
+                result.ticksCrossed++
+            }
+            if zeroForOne {
+                tick = tickNext - 1
+            } else {
+                tick = tickNext
+            }
+        } else if sqrtPrice.Cmp(start) != 0 {
+            tick = tickAtSqrtRatio(sqrtPrice)
+        }
+    }
+
+    if remaining.Sign() != 0 {
+        result.partial = true
+    }
+    result.sqrtPriceX96After = sqrtPrice
+    result.tickAfter = tick
+    return result
+}
+
+// nextInitializedTick mirrors TickBitmap.nextInitializedTickWithinOneWord: it
+// searches only the 256 spaced ticks of the current bitmap word, so swaps are
+// split into the same steps, and rounded the same way, as on-chain
+func (st *liquidityState) nextInitializedTick(tick, tickSpacing int, lte bool) (int, bool) {
+    compressed := tick / tickSpacing
+    if tick < 0 && tick%tickSpacing != 0 {
+        compressed-- // Round towards negative infinity
+    }
+
+    if lte {
+        wordStart := (compressed >> 8) << 8
+        // Largest initialized tick in [wordStart, compressed]
+        i := sort.SearchInts(st.ticks, compressed*tickSpacing+1) - 1
+        if i >= 0 && st.ticks[i] >= wordStart*tickSpacing {
+            return st.ticks[i], true
+        }
+        return wordStart * tickSpacing, false
+    }
+
+    compressed++
+    wordEnd := ((compressed >> 8) << 8) + 255
+    // Smallest initialized tick in [compressed, wordEnd]
+    i := sort.SearchInts(st.ticks, compressed*tickSpacing)
+    if i < len(st.ticks) && st.ticks[i] <= wordEnd*tickSpacing {
+        return st.ticks[i], true
+    }
+    return wordEnd * tickSpacing, false
+}
+
+// tickSpacing returns the pool's tick spacing from the factory's PoolCreated
+// event, or from the pool itself for pools that were registered directly
+func (s *PoolService) tickSpacing(ctx context.Context, poolAddress string) (int, error) {
+    pool, err := s.db.GetPool(ctx, poolAddress)
+    if err != nil {
+        return 0, err
+    }
+    if pool != nil && pool.TickSpacing > 0 {
+        return pool.TickSpacing, nil
+    }
+    return s.chain.TickSpacing(ctx, poolAddress)
+}
+
+// GetQuote simulates swapping tokenIn for the pool's other token. Exactly one
+// of amountIn (exact input) and amountOut (exact output) must be set, in base
+// units. With verify set the result is compared with the configured QuoterV2.
+func (s *PoolService) GetQuote(ctx context.Context, poolAddress, tokenIn, amountIn, amountOut string, verify bool) (*handlers.Quote, error) {
+    if !common.IsHexAddress(poolAddress) {
+        return nil, fmt.Errorf("invalid pool address %q: %w", poolAddress, handlers.ErrInvalidInput)
+    }
+    if !common.IsHexAddress(tokenIn) {
+        return nil, fmt.Errorf("invalid token_in %q: %w", tokenIn, handlers.ErrInvalidInput)
+    }
+    poolAddress = common.HexToAddress(poolAddress).Hex()
+    tokenIn = common.HexToAddress(tokenIn).Hex()
+
+    if (amountIn == "") == (amountOut == "") {
+        return nil, fmt.Errorf("exactly one of amount_in and amount_out is required: %w", handlers.ErrInvalidInput)
+    }
+    exactInput := amountIn != ""
+    raw := amountIn
+    if !exactInput {
+        raw = amountOut
+    }
+    amount, ok := new(big.Int).SetString(raw, 10)
+    if !ok || amount.Sign() <= 0 {
+        return nil, fmt.Errorf("amount %q must be a positive integer in base units: %w", raw, handlers.ErrInvalidInput)
+    }
+
+    token0Address, token1Address, err := poolTokens(ctx, s.db, poolAddress)
+    if err != nil {
+        return nil, err
+    }
+    var zeroForOne bool
+    var tokenOut string
+    switch tokenIn {
+    case token0Address:
+        zeroForOne, tokenOut = true, token1Address
+    case token1Address:
+        zeroForOne, tokenOut = false, token0Address
+    default:
+        return nil, fmt.Errorf("token %s is not in pool %s: %w", tokenIn, poolAddress, handlers.ErrInvalidInput)
+    }
+
+    fee, err := s.feeTier(ctx, poolAddress)
+    if err != nil {
+        return nil, err
+    }
+    spacing, err := s.tickSpacing(ctx, poolAddress)
+    if err != nil {
+        return nil, err
+    }
+    state, err := s.liquidityState(ctx, poolAddress, token0Address, token1Address)
+    if err != nil {
+        return nil, err
+    }
+
+    specified := new(big.Int).Set(amount)
+    if !exactInput {
+        specified.Neg(specified)
+    }
+    result := state.simulateSwap(zeroForOne, specified, fee, spacing)
+
+    tokenInMeta := s.tokens.Get(ctx, tokenIn)
+    tokenOutMeta := s.tokens.Get(ctx, tokenOut)
+    token0 := s.tokens.Get(ctx, token0Address)
+    token1 := s.tokens.Get(ctx, token1Address)
+
+    priceAfter := priceFromSqrtPriceX96(result.sqrtPriceX96After, token0.Decimals, token1.Decimals)
+    quote := &handlers.Quote{
+        PoolAddress:       poolAddress,
+        TokenIn:           tokenIn,
+        TokenOut:          tokenOut,
+        ExactInput:        exactInput,
+        AmountIn:          formatUnits(result.amountIn, tokenInMeta.Decimals),
+        AmountOut:         formatUnits(result.amountOut, tokenOutMeta.Decimals),
+        AmountInRaw:       result.amountIn.String(),
+        AmountOutRaw:      result.amountOut.String(),
+        FeeTier:           fee,
+        FeeAmountRaw:      result.feeAmount.String(),
+        PriceBefore:       formatPrice(state.price.price),
+        PriceAfter:        formatPrice(priceAfter),
+        SqrtPriceX96After: result.sqrtPriceX96After.String(),
+        TickAfter:         result.tickAfter,
+        TicksCrossed:      result.ticksCrossed,
+        Partial:           result.partial,
+    }
+    if impact := priceImpact(state.price.price, result, zeroForOne, fee, tokenInMeta.Decimals, tokenOutMeta.Decimals); impact != nil {
+        quote.PriceImpact = impact.FloatString(6)
+    }
+
+    if verify {
+        quote.OnChain = s.onChainQuote(ctx, tokenIn, tokenOut, fee, exactInput, amount, result)
+    }
+
+    return quote, nil
+}
+
+// priceImpact is the percentage by which the output falls short of trading the
+// input, less the fee, at the mid price before the swap
+func priceImpact(price *big.Rat, result *swapResult, zeroForOne bool, fee uint32, decimalsIn, decimalsOut uint8) *big.Rat {
+    if result.amountIn.Sign() == 0 || price.Sign() == 0 {
+        return nil
+    }
+
+    // Mid price of tokenIn in tokenOut units
+    mid := price
+    if !zeroForOne {
+        mid = invertPrice(price)
+    }
+
+    amountIn := new(big.Rat).SetFrac(new(big.Int).Sub(result.amountIn, result.feeAmount), pow10(decimalsIn))
+    expected := new(big.Rat).Mul(amountIn, mid)
+    if expected.Sign() == 0 {
+        return nil
+    }
+    actual := new(big.Rat).SetFrac(result.amountOut, pow10(decimalsOut))
+
+    impact := new(big.Rat).Sub(expected, actual)
+    impact.Quo(impact, expected)
+    return impact.Mul(impact, big.NewRat(100, 1))
+}
+
+// onChainQuote asks the QuoterV2 contract for the same swap through eth_call
+func (s *PoolService) onChainQuote(ctx context.Context, tokenIn, tokenOut string, fee uint32, exactInput bool, amount *big.Int, result *swapResult) *handlers.OnChainQuote {
+    if s.quoterAddress == "" {
+        return &handlers.OnChainQuote{Error: "no quoter_address configured"}
+    }
+
+    var (
+        quoted, sqrtPriceX96After *big.Int
+        err                       error
+    )
+    if exactInput {
+        quoted, sqrtPriceX96After, err = s.chain.QuoteExactInputSingle(ctx, s.quoterAddress, tokenIn, tokenOut, fee, amount)
+    } else {
+        quoted, sqrtPriceX96After, err = s.chain.QuoteExactOutputSingle(ctx, s.quoterAddress, tokenIn, tokenOut, fee, amount)
+    }
+    if err != nil {
+        return &handlers.OnChainQuote{Error: err.Error()}
+    }
+
+    simulated := result.amountOut
+    if !exactInput {
+        simulated = result.amountIn
+    }
+    matches := quoted.Cmp(simulated) == 0 && sqrtPriceX96After.Cmp(result.sqrtPriceX96After) == 0
+    if !matches {
+        log.Printf("Quote mismatch for %s -> %s: simulated %s, quoter %s", tokenIn, tokenOut, simulated, quoted)
+    }
+
+    return &handlers.OnChainQuote{
+        Amount:            quoted.String(),
+        SqrtPriceX96After: sqrtPriceX96After.String(),
+        Matches:           matches,
+    }
+}
@@ -0,0 +1,269 @@
+package services
+
+import (
+    "context"
+    "math/big"
+    "os"
+    "testing"
+
+    "github.com/ethereum/go-ethereum"
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/ethclient"
+
+    "src/internal/blockchain"
+    "src/internal/database"
+)
+
+const testFee = 3000
+
+func position(eventType string, tickLower, tickUpper int, liquidity *big.Int) *database.PoolTransaction {
+    return &database.PoolTransaction{
+        EventType: eventType,
+        TickLower: tickLower,
+        TickUpper: tickUpper,
+        Liquidity: liquidity.String(),
+    }
+}
+
+// testPoolState has 1e18 of liquidity in [-600, 600] and 2e18 in
+// [-1200, -600], with the price at tick 0
+func testPoolState() *liquidityState {
+    return newLiquidityState([]*database.PoolTransaction{
+        position("Mint", -600, 600, expandTo18Decimals(1)),
+        position("Mint", -1200, -600, expandTo18Decimals(3)),
+        position("Burn", -1200, -600, expandTo18Decimals(1)),
+    }, new(big.Int).Set(q96), 0)
+}
+
+func TestNewLiquidityState(t *testing.T) {
+    state := testPoolState()
+
+    if want := []int{-1200, -600, 600}; len(state.ticks) != len(want) || state.ticks[0] != want[0] ||
+        state.ticks[1] != want[1] || state.ticks[2] != want[2] {
+        t.Fatalf("ticks = %v, want %v", state.ticks, want)
+    }
+    for tick, want := range map[int]string{-1200: "2000000000000000000", -600: "-1000000000000000000", 600: "-1000000000000000000"} {
+        if got := state.net[tick].String(); got != want {
+            t.Errorf("liquidityNet at %d = %s, want %s", tick, got, want)
+        }
+    }
+    if got := state.liquidity.String(); got != "1000000000000000000" {
+        t.Errorf("liquidity = %s, want 1000000000000000000", got)
+    }
+}
+
+func TestSimulateSwapWithinRange(t *testing.T) {
+    state := testPoolState()
+    amount := big.NewInt(1e15)
+
+    result := state.simulateSwap(true, amount, testFee, 60)
+    step := computeSwapStep(q96, sqrtRatioAtTick(-600), expandTo18Decimals(1), amount, testFee)
+
+    if result.amountIn.Cmp(amount) != 0 {
+        t.Errorf("amountIn = %s, want %s", result.amountIn, amount)
+    }
+    if result.amountOut.Cmp(step.amountOut) != 0 {
+        t.Errorf("amountOut = %s, want %s", result.amountOut, step.amountOut)
+    }
+    if result.sqrtPriceX96After.Cmp(step.sqrtPriceNext) != 0 {
+        t.Errorf("sqrtPriceX96After = %s, want %s", result.sqrtPriceX96After, step.sqrtPriceNext)
+    }
+    if want := tickAtSqrtRatio(step.sqrtPriceNext); result.tickAfter != want {
+        t.Errorf("tickAfter = %d, want %d", result.tickAfter, want)
+    }
+    if result.ticksCrossed != 0 || result.partial {
+        t.Errorf("ticksCrossed = %d, partial = %v, want 0, false", result.ticksCrossed, result.partial)
+    }
+}
+
+// Swapping past tick -600 switches from the first position's liquidity to the second's
+func TestSimulateSwapCrossesInitializedTick(t *testing.T) {
+    state := testPoolState()
+    amount := big.NewInt(5e16)
+
+    result := state.simulateSwap(true, amount, testFee, 60)
+
+    first := computeSwapStep(q96, sqrtRatioAtTick(-600), expandTo18Decimals(1), amount, testFee)
+    if first.sqrtPriceNext.Cmp(sqrtRatioAtTick(-600)) != 0 {
+        t.Fatal("the amount does not reach tick -600")
+    }
+    remaining := new(big.Int).Sub(amount, first.amountIn)
+    remaining.Sub(remaining, first.feeAmount)
+    second := computeSwapStep(sqrtRatioAtTick(-600), sqrtRatioAtTick(-1200), expandTo18Decimals(2), remaining, testFee)
+
+    if result.ticksCrossed != 1 {
+        t.Errorf("ticksCrossed = %d, want 1", result.ticksCrossed)
+    }
+    if want := new(big.Int).Add(first.amountOut, second.amountOut); result.amountOut.Cmp(want) != 0 {
+        t.Errorf("amountOut = %s, want %s", result.amountOut, want)
+    }
+    if want := new(big.Int).Add(first.feeAmount, second.feeAmount); result.feeAmount.Cmp(want) != 0 {
+        t.Errorf("feeAmount = %s, want %s", result.feeAmount, want)
+    }
+    if result.sqrtPriceX96After.Cmp(second.sqrtPriceNext) != 0 {
+        t.Errorf("sqrtPriceX96After = %s, want %s", result.sqrtPriceX96After, second.sqrtPriceNext)
+    }
+    if result.tickAfter >= -600 || result.tickAfter <= -1200 {
+        t.Errorf("tickAfter = %d, want between -1200 and -600", result.tickAfter)
+    }
+    if result.partial {
+        t.Error("partial = true")
+    }
+}
+
+// Exact output pays out the amount exactly, and spending its input as exact
+// input receives at least as much
+func TestSimulateSwapExactOutput(t *testing.T) {
+    state := testPoolState()
+    amountOut := big.NewInt(4e16)
+
+    result := state.simulateSwap(true, new(big.Int).Neg(amountOut), testFee, 60)
+    if result.amountOut.Cmp(amountOut) != 0 {
+        t.Errorf("amountOut = %s, want %s", result.amountOut, amountOut)
+    }
+    if result.ticksCrossed != 1 || result.partial {
+        t.Errorf("ticksCrossed = %d, partial = %v, want 1, false", result.ticksCrossed, result.partial)
+    }
+
+    reverse := state.simulateSwap(true, result.amountIn, testFee, 60)
+    if reverse.amountOut.Cmp(amountOut) < 0 {
+        t.Errorf("exact input of %s receives %s, less than %s", result.amountIn, reverse.amountOut, amountOut)
+    }
+}
+
+// Once every position is crossed the swap runs to the price limit and the
+// rest of the amount is left unfilled
+func TestSimulateSwapHitsPriceLimit(t *testing.T) {
+    huge := expandTo18Decimals(1000)
+
+    tests := []struct {
+        name         string
+        zeroForOne   bool
+        amount       *big.Int
+        sqrtPrice    *big.Int
+        tick         int
+        ticksCrossed int
+    }{
+        {"exact input down to MIN_SQRT_RATIO", true, huge, new(big.Int).Add(minSqrtRatio, big.NewInt(1)), minTick, 2},
+        {"exact output down to MIN_SQRT_RATIO", true, new(big.Int).Neg(huge), new(big.Int).Add(minSqrtRatio, big.NewInt(1)), minTick, 2},
+        {"exact input up to MAX_SQRT_RATIO", false, huge, new(big.Int).Sub(maxSqrtRatio, big.NewInt(1)), maxTick - 1, 1},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            result := testPoolState().simulateSwap(tt.zeroForOne, tt.amount, testFee, 60)
+
+            if !result.partial {
+                t.Error("partial = false")
+            }
+            if result.sqrtPriceX96After.Cmp(tt.sqrtPrice) != 0 {
+                t.Errorf("sqrtPriceX96After = %s, want %s", result.sqrtPriceX96After, tt.sqrtPrice)
+            }
+            if result.tickAfter != tt.tick {
+                t.Errorf("tickAfter = %d, want %d", result.tickAfter, tt.tick)
+            }
+            if result.ticksCrossed != tt.ticksCrossed {
+                t.Errorf("ticksCrossed = %d, want %d", result.ticksCrossed, tt.ticksCrossed)
+            }
+            if result.amountIn.Cmp(huge) >= 0 || result.amountOut.Cmp(huge) >= 0 {
+                t.Errorf("filled %s in, %s out of %s", result.amountIn, result.amountOut, huge)
+            }
+        })
+    }
+}
+
+// TestSimulateSwapMatchesQuoter compares simulated swaps with QuoterV2 on a
+// node, e.g. the Hardhat node of start_pool_test_environment.sh. It is skipped
+// unless QUOTE_TEST_NODE_URL, QUOTE_TEST_QUOTER and QUOTE_TEST_POOL are set.
+func TestSimulateSwapMatchesQuoter(t *testing.T) {
+    nodeURL := os.Getenv("QUOTE_TEST_NODE_URL")
+    quoter := os.Getenv("QUOTE_TEST_QUOTER")
+    poolAddress := os.Getenv("QUOTE_TEST_POOL")
+    if nodeURL == "" || quoter == "" || poolAddress == "" {
+        t.Skip("QUOTE_TEST_NODE_URL, QUOTE_TEST_QUOTER and QUOTE_TEST_POOL are not set")
+    }
+    ctx := context.Background()
+
+    client, err := ethclient.DialContext(ctx, nodeURL)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer client.Close()
+    caller := blockchain.NewCaller(nodeURL)
+    defer caller.Close()
+
+    pool := &database.Contract{Address: common.HexToAddress(poolAddress).Hex()}
+    if err := blockchain.ResolvePoolTokens(ctx, client, pool); err != nil {
+        t.Fatal(err)
+    }
+    fee, err := caller.Fee(ctx, pool.Address)
+    if err != nil {
+        t.Fatal(err)
+    }
+    spacing, err := caller.TickSpacing(ctx, pool.Address)
+    if err != nil {
+        t.Fatal(err)
+    }
+    sqrtPriceX96, tick, err := caller.Slot0(ctx, pool.Address)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    // Rebuild the liquidity from the node's logs, the way the indexer stores them
+    logs, err := client.FilterLogs(ctx, ethereum.FilterQuery{
+        FromBlock: big.NewInt(0),
+        Addresses: []common.Address{common.HexToAddress(pool.Address)},
+        Topics:    [][]common.Hash{blockchain.PoolEventTopics[:2]},
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+    var events []*database.PoolTransaction
+    for _, vLog := range logs {
+        event, err := blockchain.ParsePoolEvent(vLog)
+        if err != nil {
+            t.Fatal(err)
+        }
+        events = append(events, position(event.EventType, event.TickLower, event.TickUpper, event.Liquidity))
+    }
+    state := newLiquidityState(events, sqrtPriceX96, tick)
+
+    for _, zeroForOne := range []bool{true, false} {
+        tokenIn, tokenOut := pool.Token0Address, pool.Token1Address
+        if !zeroForOne {
+            tokenIn, tokenOut = tokenOut, tokenIn
+        }
+
+        for _, exponent := range []int64{6, 12, 15, 18, 20} {
+            amount := new(big.Int).Exp(big.NewInt(10), big.NewInt(exponent), nil)
+            for _, exactInput := range []bool{true, false} {
+                specified := new(big.Int).Set(amount)
+                if !exactInput {
+                    specified.Neg(specified)
+                }
+                result := state.simulateSwap(zeroForOne, specified, fee, spacing)
+                // QuoterV2 reverts on exact output the pool cannot fill
+                if result.partial && !exactInput {
+                    continue
+                }
+
+                var quoted, sqrtPriceX96After *big.Int
+                simulated := result.amountOut
+                if exactInput {
+                    quoted, sqrtPriceX96After, err = caller.QuoteExactInputSingle(ctx, quoter, tokenIn, tokenOut, fee, amount)
+                } else {
+                    quoted, sqrtPriceX96After, err = caller.QuoteExactOutputSingle(ctx, quoter, tokenIn, tokenOut, fee, amount)
+                    simulated = result.amountIn
+                }
+                if err != nil {
+                    t.Fatal(err)
+                }
+
+                if quoted.Cmp(simulated) != 0 || sqrtPriceX96After.Cmp(result.sqrtPriceX96After) != 0 {
+                    t.Errorf("zeroForOne %v, exact input %v, amount %s: simulated %s at %s, quoter %s at %s",
+                        zeroForOne, exactInput, amount, simulated, result.sqrtPriceX96After, quoted, sqrtPriceX96After)
+                }
+            }
+        }
+    }
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+    "math/big"
+)
+
+// Ports of SqrtPriceMath and SwapMath from v3-core. Intermediate values use
+// arbitrary precision, but every rounding step matches the contracts so the
+// simulated amounts equal the on-chain ones to the wei.
+
+var (
+    two256       = new(big.Int).Lsh(big.NewInt(1), 256)
+    minSqrtRatio = big.NewInt(4295128739)
+    maxSqrtRatio = hexInt("fffd8963efd1fc6a506488495d951d5263988d26")
+    feeScale     = big.NewInt(feeDenominator)
+)
+
+func mulDiv(a, b, denominator *big.Int) *big.Int {
+    product := new(big.Int).Mul(a, b)
+    return product.Quo(product, denominator)
+}
+
+func mulDivRoundingUp(a, b, denominator *big.Int) *big.Int {
+    product := new(big.Int).Mul(a, b)
+    return divRoundingUp(product, denominator)
+}
+
+func divRoundingUp(x, y *big.Int) *big.Int {
+    quotient, remainder := new(big.Int).QuoRem(x, y, new(big.Int))
+    if remainder.Sign() != 0 {
+        quotient.Add(quotient, big.NewInt(1))
+    }
+    return quotient
+}
+
+// nextSqrtPriceFromAmount0RoundingUp moves the price by adding or removing
+// amount of token0. The contract falls back to a less precise formula when
+// the intermediate product overflows 256 bits, which is reproduced here.
+func nextSqrtPriceFromAmount0RoundingUp(sqrtPriceX96, liquidity, amount *big.Int, add bool) *big.Int {
+    if amount.Sign() == 0 {
+        return new(big.Int).Set(sqrtPriceX96)
+    }
+    numerator1 := new(big.Int).Lsh(liquidity, 96)
+    product := new(big.Int).Mul(amount, sqrtPriceX96)
+
+    if add {
+        denominator := new(big.Int).Add(numerator1, product)
+        if product.Cmp(two256) < 0 && denominator.Cmp(two256) < 0 {
+            return mulDivRoundingUp(numerator1, sqrtPriceX96, denominator)
+        }
+        return divRoundingUp(numerator1, new(big.Int).Add(new(big.Int).Quo(numerator1, sqrtPriceX96), amount))
+    }
+
+    denominator := new(big.Int).Sub(numerator1, product)
+    if denominator.Sign() <= 0 {
+        return nil // Not enough liquidity for the output
+    }
+    return mulDivRoundingUp(numerator1, sqrtPriceX96, denominator)
+}
+
+// nextSqrtPriceFromAmount1RoundingDown moves the price by adding or removing amount of token1
+func nextSqrtPriceFromAmount1RoundingDown(sqrtPriceX96, liquidity, amount *big.Int, add bool) *big.Int {
+    if add {
+        quotient := mulDiv(amount, q96, liquidity)
+        return quotient.Add(quotient, sqrtPriceX96)
+    }
+
+    quotient := mulDivRoundingUp(amount, q96, liquidity)
+    if sqrtPriceX96.Cmp(quotient) <= 0 {
+        return nil // Not enough liquidity for the output
+    }
+    return quotient.Sub(sqrtPriceX96, quotient)
+}
+
+func nextSqrtPriceFromInput(sqrtPriceX96, liquidity, amountIn *big.Int, zeroForOne bool) *big.Int {
+    if zeroForOne {
+        return nextSqrtPriceFromAmount0RoundingUp(sqrtPriceX96, liquidity, amountIn, true)
+    }
+    return nextSqrtPriceFromAmount1RoundingDown(sqrtPriceX96, liquidity, amountIn, true)
+}
+
+func nextSqrtPriceFromOutput(sqrtPriceX96, liquidity, amountOut *big.Int, zeroForOne bool) *big.Int {
+    if zeroForOne {
+        return nextSqrtPriceFromAmount1RoundingDown(sqrtPriceX96, liquidity, amountOut, false)
+    }
+    return nextSqrtPriceFromAmount0RoundingUp(sqrtPriceX96, liquidity, amountOut, false)
+}
+
+// swapAmount0Delta is SqrtPriceMath.getAmount0Delta
+func swapAmount0Delta(sqrtA, sqrtB, liquidity *big.Int, roundUp bool) *big.Int {
+    if sqrtA.Cmp(sqrtB) > 0 {
+        sqrtA, sqrtB = sqrtB, sqrtA
+    }
+    numerator1 := new(big.Int).Lsh(liquidity, 96)
+    numerator2 := new(big.Int).Sub(sqrtB, sqrtA)
+
+    if roundUp {
+        return divRoundingUp(mulDivRoundingUp(numerator1, numerator2, sqrtB), sqrtA)
+    }
+    amount := mulDiv(numerator1, numerator2, sqrtB)
+    return amount.Quo(amount, sqrtA)
+}
+
+// swapAmount1Delta is SqrtPriceMath.getAmount1Delta
+func swapAmount1Delta(sqrtA, sqrtB, liquidity *big.Int, roundUp bool) *big.Int {
+    if sqrtA.Cmp(sqrtB) > 0 {
+        sqrtA, sqrtB = sqrtB, sqrtA
+    }
+    difference := new(big.Int).Sub(sqrtB, sqrtA)
+
+    if roundUp {
+        return mulDivRoundingUp(liquidity, difference, q96)
+    }
+    return mulDiv(liquidity, difference, q96)
+}
+
+// swapStep is the result of SwapMath.computeSwapStep
+type swapStep struct {
+    sqrtPriceNext *big.Int
+    amountIn      *big.Int
+    amountOut     *big.Int
+    feeAmount     *big.Int
+}
+
+// computeSwapStep swaps within a single liquidity range, towards the target
+// price. amountRemaining is positive for exact input and negative for exact
+// output. It returns nil when the liquidity cannot provide the output.
+func computeSwapStep(sqrtCurrent, sqrtTarget, liquidity, amountRemaining *big.Int, fee uint32) *swapStep {
+    zeroForOne := sqrtCurrent.Cmp(sqrtTarget) >= 0
+    exactIn := amountRemaining.Sign() >= 0
+    feePips := big.NewInt(int64(fee))
+    step := &swapStep{}
+
+    var amountIn, amountOut *big.Int
+    if exactIn {
+        remainingLessFee := mulDiv(amountRemaining, new(big.Int).Sub(feeScale, feePips), feeScale)
+        if zeroForOne {
+            amountIn = swapAmount0Delta(sqrtTarget, sqrtCurrent, liquidity, true)
+        } else {
+            amountIn = swapAmount1Delta(sqrtCurrent, sqrtTarget, liquidity, true)
+        }
+        if remainingLessFee.Cmp(amountIn) >= 0 {
+            step.sqrtPriceNext = sqrtTarget
+        } else {
+            step.sqrtPriceNext = nextSqrtPriceFromInput(sqrtCurrent, liquidity, remainingLessFee, zeroForOne)
+        }
+    } else {
+        if zeroForOne {
+            amountOut = swapAmount1Delta(sqrtTarget, sqrtCurrent, liquidity, false)
+        } else {
+            amountOut = swapAmount0Delta(sqrtCurrent, sqrtTarget, liquidity, false)
+        }
+        if new(big.Int).Neg(amountRemaining).Cmp(amountOut) >= 0 {
+            step.sqrtPriceNext = sqrtTarget
+        } else {
+            step.sqrtPriceNext = nextSqrtPriceFromOutput(sqrtCurrent, liquidity, new(big.Int).Neg(amountRemaining), zeroForOne)
+        }
+    }
+    if step.sqrtPriceNext == nil {
+        return nil
+    }
+
+    reachedTarget := step.sqrtPriceNext.Cmp(sqrtTarget) == 0
+    if zeroForOne {
+        if reachedTarget && exactIn {
+            step.amountIn = amountIn
+        } else {
+            step.amountIn = swapAmount0Delta(step.sqrtPriceNext, sqrtCurrent, liquidity, true)
+        }
+        if reachedTarget && !exactIn {
+            step.amountOut = amountOut
+        } else {
+            step.amountOut = swapAmount1Delta(step.sqrtPriceNext, sqrtCurrent, liquidity, false)
+        }
+    } else {
+        if reachedTarget && exactIn {
+            step.amountIn = amountIn
+        } else {
+            step.amountIn = swapAmount1Delta(sqrtCurrent, step.sqrtPriceNext, liquidity, true)
+        }
+        if reachedTarget && !exactIn {
+            step.amountOut = amountOut
+        } else {
+            step.amountOut = swapAmount0Delta(sqrtCurrent, step.sqrtPriceNext, liquidity, false)
+        }
+    }
+
+    // Never pay out more than the requested output
+    if !exactIn && step.amountOut.Cmp(new(big.Int).Neg(amountRemaining)) > 0 {
+        step.amountOut = new(big.Int).Neg(amountRemaining)
+    }
+
+    if exactIn && !reachedTarget {
+        // Whatever input is left after moving the price is kept as fee
+        step.feeAmount = new(big.Int).Sub(amountRemaining, step.amountIn)
+    } else {
+        step.feeAmount = mulDivRoundingUp(step.amountIn, feePips, new(big.Int).Sub(feeScale, feePips))
+    }
+
+    return step
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+    "math/big"
+    "testing"
+)
+
+// encodePriceSqrt returns sqrt(reserve1 / reserve0) as a Q64.96, like the v3-core test helper
+func encodePriceSqrt(reserve1, reserve0 int64) *big.Int {
+    ratio := new(big.Int).Lsh(big.NewInt(reserve1), 192)
+    ratio.Quo(ratio, big.NewInt(reserve0))
+    return ratio.Sqrt(ratio)
+}
+
+func expandTo18Decimals(n int64) *big.Int {
+    return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18))
+}
+
+// Vectors from the SwapMath.computeSwapStep cases of the v3-core test suite
+func TestComputeSwapStep(t *testing.T) {
+    sqrtP := bigInt(t, "20282409603651670423947251286016")
+
+    tests := []struct {
+        name      string
+        price     *big.Int
+        target    *big.Int
+        liquidity *big.Int
+        amount    *big.Int // Positive for exact input, negative for exact output
+        fee       uint32
+        sqrtNext  *big.Int // Expected price after the step, nil when it is the target
+        amountIn  string
+        amountOut string
+        feeAmount string
+    }{
+        {
+            name:      "exact in capped at the price target, one for zero",
+            price:     encodePriceSqrt(1, 1),
+            target:    encodePriceSqrt(101, 100),
+            liquidity: expandTo18Decimals(2),
+            amount:    expandTo18Decimals(1),
+            fee:       600,
+            amountIn:  "9975124224178055",
+            amountOut: "9925619580021728",
+            feeAmount: "5988667735148",
+        },
+        {
+            name:      "exact out capped at the price target, one for zero",
+            price:     encodePriceSqrt(1, 1),
+            target:    encodePriceSqrt(101, 100),
+            liquidity: expandTo18Decimals(2),
+            amount:    new(big.Int).Neg(expandTo18Decimals(1)),
+            fee:       600,
+            amountIn:  "9975124224178055",
+            amountOut: "9925619580021728",
+            feeAmount: "5988667735148",
+        },
+        {
+            name:      "exact in fully spent, one for zero",
+            price:     encodePriceSqrt(1, 1),
+            target:    encodePriceSqrt(1000, 100),
+            liquidity: expandTo18Decimals(2),
+            amount:    expandTo18Decimals(1),
+            fee:       600,
+            sqrtNext:  nextSqrtPriceFromInput(encodePriceSqrt(1, 1), expandTo18Decimals(2), big.NewInt(999400000000000000), false),
+            amountIn:  "999400000000000000",
+            amountOut: "666399946655997866",
+            feeAmount: "600000000000000",
+        },
+        {
+            name:      "exact out fully received, one for zero",
+            price:     encodePriceSqrt(1, 1),
+            target:    encodePriceSqrt(10000, 100),
+            liquidity: expandTo18Decimals(2),
+            amount:    new(big.Int).Neg(expandTo18Decimals(1)),
+            fee:       600,
+            sqrtNext:  nextSqrtPriceFromOutput(encodePriceSqrt(1, 1), expandTo18Decimals(2), expandTo18Decimals(1), false),
+            amountIn:  "2000000000000000000",
+            amountOut: "1000000000000000000",
+            feeAmount: "1200720432259356",
+        },
+        {
+            name:      "amount out capped at the desired amount",
+            price:     bigInt(t, "417332158212080721273783715441582"),
+            target:    bigInt(t, "1452870262520218020823638996"),
+            liquidity: bigInt(t, "159344665391607089467575320103"),
+            amount:    big.NewInt(-1),
+            fee:       1,
+            sqrtNext:  bigInt(t, "417332158212080721273783715441581"),
+            amountIn:  "1",
+            amountOut: "1",
+            feeAmount: "1",
+        },
+        {
+            name:      "target price of 1 uses partial input",
+            price:     big.NewInt(2),
+            target:    big.NewInt(1),
+            liquidity: big.NewInt(1),
+            amount:    bigInt(t, "3915081100057732413702495386755767"),
+            fee:       1,
+            amountIn:  "39614081257132168796771975168",
+            amountOut: "0",
+            feeAmount: "39614120871253040049813",
+        },
+        {
+            name:      "entire input taken as fee",
+            price:     big.NewInt(2413),
+            target:    bigInt(t, "79887613182836312"),
+            liquidity: bigInt(t, "1985041575832132834610021537970"),
+            amount:    big.NewInt(10),
+            fee:       1872,
+            sqrtNext:  big.NewInt(2413),
+            amountIn:  "0",
+            amountOut: "0",
+            feeAmount: "10",
+        },
+        {
+            name:      "intermediate insufficient liquidity, exact out zero for one",
+            price:     sqrtP,
+            target:    new(big.Int).Quo(new(big.Int).Mul(sqrtP, big.NewInt(11)), big.NewInt(10)),
+            liquidity: big.NewInt(1024),
+            amount:    big.NewInt(-4),
+            fee:       3000,
+            amountIn:  "26215",
+            amountOut: "0",
+            feeAmount: "79",
+        },
+        {
+            name:      "intermediate insufficient liquidity, exact out one for zero",
+            price:     sqrtP,
+            target:    new(big.Int).Quo(new(big.Int).Mul(sqrtP, big.NewInt(9)), big.NewInt(10)),
+            liquidity: big.NewInt(1024),
+            amount:    big.NewInt(-263000),
+            fee:       3000,
+            amountIn:  "1",
+            amountOut: "26214",
+            feeAmount: "1",
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            step := computeSwapStep(tt.price, tt.target, tt.liquidity, tt.amount, tt.fee)
+            if step == nil {
+                t.Fatal("computeSwapStep() = nil")
+            }
+
+            sqrtNext := tt.sqrtNext
+            if sqrtNext == nil {
+                sqrtNext = tt.target
+            }
+            if step.sqrtPriceNext.Cmp(sqrtNext) != 0 {
+                t.Errorf("sqrtPriceNext = %s, want %s", step.sqrtPriceNext, sqrtNext)
+            }
+            if got := step.amountIn.String(); got != tt.amountIn {
+                t.Errorf("amountIn = %s, want %s", got, tt.amountIn)
+            }
+            if got := step.amountOut.String(); got != tt.amountOut {
+                t.Errorf("amountOut = %s, want %s", got, tt.amountOut)
+            }
+            if got := step.feeAmount.String(); got != tt.feeAmount {
+                t.Errorf("feeAmount = %s, want %s", got, tt.feeAmount)
+            }
+
+            // Exact input never spends more than the amount, exact output never pays out more
+            if tt.amount.Sign() > 0 {
+                spent := new(big.Int).Add(step.amountIn, step.feeAmount)
+                if spent.Cmp(tt.amount) > 0 {
+                    t.Errorf("spent %s of %s", spent, tt.amount)
+                }
+            } else if step.amountOut.Cmp(new(big.Int).Neg(tt.amount)) > 0 {
+                t.Errorf("paid out %s of %s", step.amountOut, new(big.Int).Neg(tt.amount))
+            }
+        })
+    }
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+    "math/big"
+)
+
+// Tick range of a Uniswap V3 pool
+const (
+    minTick = -887272
+    maxTick = 887272
+)
+
+var (
+    q96        = new(big.Int).Lsh(big.NewInt(1), 96)
+    q128       = new(big.Int).Lsh(big.NewInt(1), 128)
+    maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+    // sqrtRatioMultipliers[i] is 2^128 / sqrt(1.0001^(2^i)), from TickMath.sol
+    sqrtRatioMultipliers = []*big.Int{
+        hexInt("fffcb933bd6fad37aa2d162d1a594001"),
+        hexInt("fff97272373d413259a46990580e213a"),
+        hexInt("fff2e50f5f656932ef12357cf3c7fdcc"),
+        hexInt("ffe5caca7e10e4e61c3624eaa0941cd0"),
+        hexInt("ffcb9843d60f6159c9db58835c926644"),
+        hexInt("ff973b41fa98c081472e6896dfb254c0"),
+        hexInt("ff2ea16466c96a3843ec78b326b52861"),
+        hexInt("fe5dee046a99a2a811c461f1969c3053"),
+        hexInt("fcbe86c7900a88aedcffc83b479aa3a4"),
+        hexInt("f987a7253ac413176f2b074cf7815e54"),
+        hexInt("f3392b0822b70005940c7a398e4b70f3"),
+        hexInt("e7159475a2c29b7443b29c7fa6e889d9"),
+        hexInt("d097f3bdfd2022b8845ad8f792aa5825"),
+        hexInt("a9f746462d870fdf8a65dc1f90e061e5"),
+        hexInt("70d869a156d2a1b890bb3df62baf32f7"),
+        hexInt("31be135f97d08fd981231505542fcfa6"),
+        hexInt("9aa508b5b7a84e1c677de54f3e99bc9"),
+        hexInt("5d6af8dedb81196699c329225ee604"),
+        hexInt("2216e584f5fa1ea926041bedfe98"),
+        hexInt("48a170391f7dc42444e8fa2"),
+    }
+)
+
+// sqrtRatioAtTick returns sqrt(1.0001^tick) as a Q64.96, rounded exactly
+// like TickMath.getSqrtRatioAtTick. The tick must be within [minTick, maxTick].
+func sqrtRatioAtTick(tick int) *big.Int {
+    absTick := tick
+    if absTick < 0 {
+        absTick = -absTick
+    }
+
+    ratio := new(big.Int).Set(q128)
+    if absTick&1 != 0 {
+        ratio.Set(sqrtRatioMultipliers[0])
+    }
+    for i := 1; i < len(sqrtRatioMultipliers); i++ {
+        if absTick&(1<<i) != 0 {
+            ratio.Mul(ratio, sqrtRatioMultipliers[i])
+            ratio.Rsh(ratio, 128)
+        }
+    }
+
+    if tick > 0 {
+        ratio.Quo(maxUint256, ratio)
+    }
+
+    // Round up from Q128.128 to Q64.96
+    remainder := new(big.Int).And(ratio, big.NewInt(1<<32-1))
+    ratio.Rsh(ratio, 32)
+    if remainder.Sign() != 0 {
+        ratio.Add(ratio, big.NewInt(1))
+    }
+    return ratio
+}
+
+// tickAtSqrtRatio returns the greatest tick whose sqrt ratio is at most sqrtPriceX96
+func tickAtSqrtRatio(sqrtPriceX96 *big.Int) int {
+    low, high := minTick, maxTick
+    for low < high {
+        mid := low + (high-low+1)/2
+        if sqrtRatioAtTick(mid).Cmp(sqrtPriceX96) <= 0 {
+            low = mid
+        } else {
+            high = mid - 1
+        }
+    }
+    return low
+}
+
+// priceAtTick returns 1.0001^tick as the price of token0 in token1 units
+func priceAtTick(tick int, decimals0, decimals1 uint8) *big.Rat {
+    return priceFromSqrtPriceX96(sqrtRatioAtTick(tick), decimals0, decimals1)
+}
+
+func hexInt(value string) *big.Int {
+    parsed, ok := new(big.Int).SetString(value, 16)
+    if !ok {
+        panic("invalid hex constant " + value)
+    }
+    return parsed
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+    "math/big"
+    "testing"
+)
+
+func bigInt(t *testing.T, value string) *big.Int {
+    t.Helper()
+    parsed, ok := new(big.Int).SetString(value, 10)
+    if !ok {
+        t.Fatalf("invalid integer %q", value)
+    }
+    return parsed
+}
+
+// Expected values are TickMath.getSqrtRatioAtTick results from the v3-core test snapshots
+func TestSqrtRatioAtTick(t *testing.T) {
+    tests := []struct {
+        tick int
+        want string
+    }{
+        {minTick, "4295128739"},
+        {minTick + 1, "4295343490"},
+        {-500000, "1101692437043807371"},
+        {-50000, "6504256538020985011912221507"},
+        {-250, "78244023372248365697264290337"},
+        {-50, "79030349367926598376800521322"},
+        {-1, "79224201403219477170569942574"},
+        {0, "79228162514264337593543950336"},
+        {1, "79232123823359799118286999568"},
+        {50, "79426470787362580746886972461"},
+        {100, "79625275426524748796330556128"},
+        {250, "80224679980005306637834519095"},
+        {500, "81233731461783161732293370115"},
+        {1000, "83290069058676223003182343270"},
+        {2500, "89776708723587163891445672585"},
+        {3000, "92049301871182272007977902845"},
+        {4000, "96768528593268422080558758223"},
+        {5000, "101729702841318637793976746270"},
+        {50000, "965075977353221155028623082916"},
+        {150000, "143194173941309278083010301478497"},
+        {250000, "21246587762933397357449903968194344"},
+        {500000, "5697689776495288729098254600827762987878"},
+        {738203, "847134979253254120489401328389043031315994541"},
+        {maxTick - 1, "1461373636630004318706518188784493106690254656249"},
+        {maxTick, "1461446703485210103287273052203988822378723970342"},
+    }
+
+    for _, tt := range tests {
+        if got := sqrtRatioAtTick(tt.tick); got.Cmp(bigInt(t, tt.want)) != 0 {
+            t.Errorf("sqrtRatioAtTick(%d) = %s, want %s", tt.tick, got, tt.want)
+        }
+    }
+
+    if got := sqrtRatioAtTick(minTick); got.Cmp(minSqrtRatio) != 0 {
+        t.Errorf("sqrtRatioAtTick(minTick) = %s, want MIN_SQRT_RATIO %s", got, minSqrtRatio)
+    }
+    if got := sqrtRatioAtTick(maxTick); got.Cmp(maxSqrtRatio) != 0 {
+        t.Errorf("sqrtRatioAtTick(maxTick) = %s, want MAX_SQRT_RATIO %s", got, maxSqrtRatio)
+    }
+}
+
+func TestTickAtSqrtRatio(t *testing.T) {
+    tests := []struct {
+        name         string
+        sqrtPriceX96 *big.Int
+        want         int
+    }{
+        {"min sqrt ratio", minSqrtRatio, minTick},
+        {"min sqrt ratio + 1", new(big.Int).Add(minSqrtRatio, big.NewInt(1)), minTick},
+        {"price of tick 1 - 1", new(big.Int).Sub(sqrtRatioAtTick(1), big.NewInt(1)), 0},
+        {"price 1", q96, 0},
+        {"price of tick -1", sqrtRatioAtTick(-1), -1},
+        {"just below price 1", new(big.Int).Sub(q96, big.NewInt(1)), -1},
+        {"max sqrt ratio - 1", new(big.Int).Sub(maxSqrtRatio, big.NewInt(1)), maxTick - 1},
+    }
+
+    for _, tt := range tests {
+        if got := tickAtSqrtRatio(tt.sqrtPriceX96); got != tt.want {
+            t.Errorf("tickAtSqrtRatio(%s) = %d, want %d", tt.name, got, tt.want)
+        }
+    }
+
+    // The greatest tick at or below the ratio of every tick is the tick itself
+    for _, tick := range []int{minTick, -887220, -60, -1, 0, 1, 60, 887220, maxTick - 1} {
+        if got := tickAtSqrtRatio(sqrtRatioAtTick(tick)); got != tick {
+            t.Errorf("tickAtSqrtRatio(sqrtRatioAtTick(%d)) = %d", tick, got)
+        }
+    }
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+    "context"
+    "log"
+    "math/big"
+    "strings"
+    "sync"
+
+    "github.com/ethereum/go-ethereum/common"
+
+    "src/internal/database"
+)
+
+// defaultDecimals is assumed for tokens whose metadata cannot be read
+const defaultDecimals = 18
+
+// ChainReader performs read-only calls against the node
+type ChainReader interface {
+    TokenMetadata(ctx context.Context, tokenAddress string) (*database.TokenMetadata, error)
+    Slot0(ctx context.Context, poolAddress string) (sqrtPriceX96 *big.Int, tick int, err error)
+    BalanceOf(ctx context.Context, tokenAddress, holder string) (*big.Int, error)
+    Fee(ctx context.Context, poolAddress string) (uint32, error)
+    Observe(ctx context.Context, poolAddress string, secondsAgos []uint32) (tickCumulatives []*big.Int, err error)
+    TickSpacing(ctx context.Context, poolAddress string) (int, error)
+    QuoteExactInputSingle(ctx context.Context, quoter, tokenIn, tokenOut string, fee uint32, amountIn *big.Int) (amountOut, sqrtPriceX96After *big.Int, err error)
+    QuoteExactOutputSingle(ctx context.Context, quoter, tokenIn, tokenOut string, fee uint32, amountOut *big.Int) (amountIn, sqrtPriceX96After *big.Int, err error)
+    ProxySlots(ctx context.Context, proxyAddress string) (implementation, admin, beacon string, err error)
+    CodeHash(ctx context.Context, address string) (string, error)
+    Version(ctx context.Context, address string) (string, error)
+}
+
+// TokenRegistry resolves token metadata from memory, then Mongo, then the chain
+type TokenRegistry struct {
+    db    database.Service
+    chain ChainReader
+    mu    sync.RWMutex
+    cache map[string]*database.TokenMetadata
+}
+
+func NewTokenRegistry(db database.Service, chain ChainReader) *TokenRegistry {
+    return &TokenRegistry{
+        db:    db,
+        chain: chain,
+        cache: make(map[string]*database.TokenMetadata),
+    }
+}
+
+// Get returns the metadata of a token. Tokens whose metadata cannot be read
+// are reported with 18 decimals and are not cached, so the lookup is retried.
+func (r *TokenRegistry) Get(ctx context.Context, tokenAddress string) *database.TokenMetadata {
+    address := common.HexToAddress(tokenAddress).Hex()
+
+    r.mu.RLock()
+    metadata, ok := r.cache[address]
+    r.mu.RUnlock()
+    if ok {
+        return metadata
+    }
+
+    metadata, err := r.db.GetTokenMetadata(ctx, address)
+    if err != nil {
+        log.Printf("Failed to load token metadata: %v", err)
+    }
+
+    if metadata == nil {
+        metadata, err = r.chain.TokenMetadata(ctx, address)
+        if err != nil {
+            log.Printf("Failed to fetch token metadata: %v", err)
+            return &database.TokenMetadata{Address: address, Decimals: defaultDecimals}
+        }
+        if err := r.db.SaveTokenMetadata(ctx, metadata); err != nil {
+            log.Printf("Failed to save token metadata: %v", err)
+        }
+    }
+
+    r.mu.Lock()
+    r.cache[address] = metadata
+    r.mu.Unlock()
+    return metadata
+}
+
+// formatUnits renders an integer amount of base units as a decimal string
+// with the given number of decimals, e.g. 1500000 with 6 decimals is "1.500000"
+func formatUnits(amount *big.Int, decimals uint8) string {
+    sign := ""
+    value := new(big.Int).Set(amount)
+    if value.Sign() < 0 {
+        sign = "-"
+        value.Neg(value)
+    }
+
+    digits := value.String()
+    if decimals == 0 {
+        return sign + digits
+    }
+
+    if len(digits) <= int(decimals) {
+        digits = strings.Repeat("0", int(decimals)-len(digits)+1) + digits
+    }
+    point := len(digits) - int(decimals)
+    return sign + digits[:point] + "." + digits[point:]
+}
+
+// scaleUnits converts an amount between two decimal precisions, truncating
+// when the target has fewer decimals
+func scaleUnits(amount *big.Int, from, to uint8) *big.Int {
+    switch {
+    case from == to:
+        return new(big.Int).Set(amount)
+    case from < to:
+        factor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(to-from)), nil)
+        return new(big.Int).Mul(amount, factor)
+    default:
+        factor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(from-to)), nil)
+        return new(big.Int).Quo(amount, factor)
+    }
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+    "context"
+    "fmt"
+    "slices"
+
+    "github.com/ethereum/go-ethereum/common"
+
+    "src/internal/database"
+    "src/internal/handlers"
+)
+
+// TokenAdminService reports the administrative state of registered tokens
+type TokenAdminService struct {
+    db     database.Service
+    tokens *TokenRegistry
+}
+
+func NewTokenAdminService(db database.Service, tokens *TokenRegistry) *TokenAdminService {
+    return &TokenAdminService{
+        db:     db,
+        tokens: tokens,
+    }
+}
+
+// GetTokenAdminState replays the token's administrative events in order. With
+// confirmed finality only confirmed events are replayed.
+func (s *TokenAdminService) GetTokenAdminState(ctx context.Context, tokenAddress, finality string) (*handlers.TokenAdminState, error) {
+    if !common.IsHexAddress(tokenAddress) {
+        return nil, fmt.Errorf("invalid token address %q: %w", tokenAddress, handlers.ErrInvalidInput)
+    }
+    tokenAddress = common.HexToAddress(tokenAddress).Hex()
+
+    contract, err := s.db.GetContract(ctx, tokenAddress)
+    if err != nil {
+        return nil, err
+    }
+    if contract == nil || contract.Kind != database.ContractKindERC20 {
+        return nil, fmt.Errorf("%s is not a registered token: %w", tokenAddress, handlers.ErrNotFound)
+    }
+
+    events, err := s.db.GetTokenAdminEvents(ctx, tokenAddress, finality == handlers.FinalityConfirmed)
+    if err != nil {
+        return nil, err
+    }
+
+    state := &handlers.TokenAdminState{
+        TokenAddress: tokenAddress,
+        Symbol:       s.tokens.Get(ctx, tokenAddress).Symbol,
+        Blacklist:    make([]handlers.BlacklistEntry, 0),
+        History:      make([]handlers.TokenAdminEvent, 0, len(events)),
+        Finality:     finality,
+    }
+
+    // Accounts in the order they were (last) blacklisted
+    var listed []string
+    entries := make(map[string]handlers.BlacklistEntry)
+
+    for _, event := range events {
+        switch event.EventType {
+        case "Paused":
+            state.Paused = true
+        case "Unpaused":
+            state.Paused = false
+        case "OwnershipTransferred":
+            state.Owner = event.NewOwner
+        case "Blacklisted":
+            if _, ok := entries[event.Account]; !ok {
+                listed = append(listed, event.Account)
+                entries[event.Account] = handlers.BlacklistEntry{
+                    Account:     event.Account,
+                    BlockNumber: event.BlockNumber,
+                    TxHash:      event.TxHash,
+                    Timestamp:   event.Timestamp,
+                }
+            }
+        case "UnBlacklisted":
+            delete(entries, event.Account)
+        }
+
+        state.History = append(state.History, handlers.TokenAdminEvent{
+            EventType:     event.EventType,
+            Account:       event.Account,
+            PreviousOwner: event.PreviousOwner,
+            NewOwner:      event.NewOwner,
+            BlockNumber:   event.BlockNumber,
+            TxHash:        event.TxHash,
+            LogIndex:      event.LogIndex,
+            Timestamp:     event.Timestamp,
+            Status:        event.Status,
+        })
+    }
+
+    // An account listed, removed and listed again appears in listed twice
+    for i := len(listed) - 1; i >= 0; i-- {
+        if entry, ok := entries[listed[i]]; ok {
+            state.Blacklist = append(state.Blacklist, entry)
+            delete(entries, listed[i])
+        }
+    }
+    slices.Reverse(state.Blacklist)
+
+    return state, nil
+}
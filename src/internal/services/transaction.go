@@ -2,36 +2,58 @@ package services
 
 import (
     "context"
+    "fmt"
     "math/big"
 
+    "github.com/ethereum/go-ethereum/common"
+
     "src/internal/handlers"
     "src/internal/database"
 )
 
 type TransactionService struct {
-    db database.Service
+    db     database.Service
+    tokens *TokenRegistry
 }
 
-func NewTransactionService(db database.Service) *TransactionService {
+func NewTransactionService(db database.Service, tokens *TokenRegistry) *TransactionService {
     return &TransactionService{
-        db: db,
+        db:     db,
+        tokens: tokens,
     }
 }
 
-func (s *TransactionService) GetAccountSummary(ctx context.Context, accountAddress string) (*handlers.AccountSummary, error) {
-    transactions, err := s.db.GetTransactionsByAccount(ctx, accountAddress)
+// accountDecimals is the precision account-wide totals are reported in
+const accountDecimals = 18
+
+// tokenTotals accumulates an account's flows for a single token
+type tokenTotals struct {
+    minted   *big.Int
+    burned   *big.Int
+    received *big.Int
+    sent     *big.Int
+}
+
+func (s *TransactionService) GetAccountSummary(ctx context.Context, accountAddress string, finality string) (*handlers.AccountSummary, error) {
+    if !common.IsHexAddress(accountAddress) {
+        return nil, fmt.Errorf("invalid account address %q: %w", accountAddress, handlers.ErrInvalidInput)
+    }
+    if finality != handlers.FinalityLatest && finality != handlers.FinalityConfirmed {
+        return nil, fmt.Errorf("invalid finality %q, expected confirmed or latest: %w", finality, handlers.ErrInvalidInput)
+    }
+
+    // Events are stored with checksummed addresses
+    accountAddress = common.HexToAddress(accountAddress).Hex()
+
+    transactions, err := s.db.GetTransactionsByAccount(ctx, accountAddress, finality == handlers.FinalityConfirmed)
     if err != nil {
         return nil, err
     }
 
     // Map to store token-specific summaries
-    tokenSummaries := make(map[string]*struct {
-        minted *big.Int
-        burned *big.Int
-    })
+    tokenSummaries := make(map[string]*tokenTotals)
 
-    totalMinted := new(big.Int)
-    totalBurned := new(big.Int)
+    totals := newTokenTotals()
 
     // Process all transactions
     for _, tx := range transactions {
@@ -40,21 +62,40 @@ func (s *TransactionService) GetAccountSummary(ctx context.Context, accountAddre
 
         // Initialize token summary if not exists
         if _, exists := tokenSummaries[tx.TokenAddress]; !exists {
-            tokenSummaries[tx.TokenAddress] = &struct {
-                minted *big.Int
-                burned *big.Int
-            }{
-                minted: new(big.Int),
-                burned: new(big.Int),
+            tokenSummaries[tx.TokenAddress] = newTokenTotals()
+        }
+        tokenSum := tokenSummaries[tx.TokenAddress]
+
+        from, to := transferEndpoints(tx)
+        switch tx.EventType {
+        case "Mint":
+            tokenSum.minted.Add(tokenSum.minted, amount)
+        case "Burn":
+            tokenSum.burned.Add(tokenSum.burned, amount)
+        case "Transfer":
+            // A transfer to self counts on both sides
+            if to == accountAddress {
+                tokenSum.received.Add(tokenSum.received, amount)
+            }
+            if from == accountAddress {
+                tokenSum.sent.Add(tokenSum.sent, amount)
             }
         }
+    }
 
-        if tx.EventType == "Mint" {
-            tokenSummaries[tx.TokenAddress].minted.Add(tokenSummaries[tx.TokenAddress].minted, amount)
-            totalMinted.Add(totalMinted, amount)
-        } else if tx.EventType == "Burn" {
-            tokenSummaries[tx.TokenAddress].burned.Add(tokenSummaries[tx.TokenAddress].burned, amount)
-            totalBurned.Add(totalBurned, amount)
+    // The running balances include pending events, so confirmed summaries
+    // are derived from the confirmed events instead
+    balances := make(map[string]*database.Balance)
+    if finality != handlers.FinalityConfirmed {
+        stored, err := s.db.GetBalances(ctx, accountAddress)
+        if err != nil {
+            return nil, err
+        }
+        for _, balance := range stored {
+            balances[balance.TokenAddress] = balance
+            if _, exists := tokenSummaries[balance.TokenAddress]; !exists {
+                tokenSummaries[balance.TokenAddress] = newTokenTotals()
+            }
         }
     }
 
@@ -62,34 +103,89 @@ func (s *TransactionService) GetAccountSummary(ctx context.Context, accountAddre
     summary := &handlers.AccountSummary{
         AccountAddress: accountAddress,
         Tokens:        make([]handlers.TokenSummary, 0),
-        TotalMinted:   weiToEther(totalMinted.String()),
-        TotalBurned:   weiToEther(totalBurned.String()),
-        NetBalance:    weiToEther(new(big.Int).Sub(totalMinted, totalBurned).String()),
+        Finality:      finality,
     }
 
-    // Add token-specific summaries
+    // Add token-specific summaries. Account totals add up amounts of tokens with
+    // different decimals, so each token is normalised to 18 decimals first.
+    netBalance := new(big.Int)
     for tokenAddr, tokenSum := range tokenSummaries {
-        balance := new(big.Int).Sub(tokenSum.minted, tokenSum.burned)
+        metadata := s.tokens.Get(ctx, tokenAddr)
+
+        balance := tokenSum.balance()
+        var balanceBlock uint64
+        if stored, ok := balances[tokenAddr]; ok {
+            balance.SetString(stored.Balance, 10)
+            balanceBlock = stored.BlockNumber
+        }
+
+        totals.add(tokenSum, metadata.Decimals)
+        netBalance.Add(netBalance, scaleUnits(balance, metadata.Decimals, accountDecimals))
+
         summary.Tokens = append(summary.Tokens, handlers.TokenSummary{
             TokenAddress:   tokenAddr,
-            TotalMinted:   weiToEther(tokenSum.minted.String()),
-            TotalBurned:   weiToEther(tokenSum.burned.String()),
-            CurrentBalance: weiToEther(balance.String()),
+            Symbol:        metadata.Symbol,
+            Name:          metadata.Name,
+            Decimals:      metadata.Decimals,
+            TotalMinted:   formatUnits(tokenSum.minted, metadata.Decimals),
+            TotalMintedRaw: tokenSum.minted.String(),
+            TotalBurned:   formatUnits(tokenSum.burned, metadata.Decimals),
+            TotalBurnedRaw: tokenSum.burned.String(),
+            TotalReceived: formatUnits(tokenSum.received, metadata.Decimals),
+            TotalReceivedRaw: tokenSum.received.String(),
+            TotalSent:     formatUnits(tokenSum.sent, metadata.Decimals),
+            TotalSentRaw:  tokenSum.sent.String(),
+            CurrentBalance: formatUnits(balance, metadata.Decimals),
+            CurrentBalanceRaw: balance.String(),
+            BalanceBlock:  balanceBlock,
         })
     }
 
+    summary.TotalMinted = formatUnits(totals.minted, accountDecimals)
+    summary.TotalBurned = formatUnits(totals.burned, accountDecimals)
+    summary.TotalReceived = formatUnits(totals.received, accountDecimals)
+    summary.TotalSent = formatUnits(totals.sent, accountDecimals)
+    summary.NetBalance = formatUnits(netBalance, accountDecimals)
+
     return summary, nil
 }
 
-// Helper function to convert wei to ether
-func weiToEther(wei string) string {
-    weiInt := new(big.Int)
-    weiInt.SetString(wei, 10)
-    
-    divisor := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
-    
-    etherBig := new(big.Float).SetInt(weiInt)
-    etherBig.Quo(etherBig, new(big.Float).SetInt(divisor))
-    
-    return etherBig.Text('f', 18)
-}
\ No newline at end of file
+func newTokenTotals() *tokenTotals {
+    return &tokenTotals{
+        minted:   new(big.Int),
+        burned:   new(big.Int),
+        received: new(big.Int),
+        sent:     new(big.Int),
+    }
+}
+
+// add accumulates other, expressed with the given decimals, in accountDecimals
+func (t *tokenTotals) add(other *tokenTotals, decimals uint8) {
+    t.minted.Add(t.minted, scaleUnits(other.minted, decimals, accountDecimals))
+    t.burned.Add(t.burned, scaleUnits(other.burned, decimals, accountDecimals))
+    t.received.Add(t.received, scaleUnits(other.received, decimals, accountDecimals))
+    t.sent.Add(t.sent, scaleUnits(other.sent, decimals, accountDecimals))
+}
+
+// balance derives the holder's balance from the accumulated flows
+func (t *tokenTotals) balance() *big.Int {
+    balance := new(big.Int).Add(t.minted, t.received)
+    balance.Sub(balance, t.burned)
+    return balance.Sub(balance, t.sent)
+}
+
+// transferEndpoints returns the sender and recipient of a transfer, filling
+// them in for mints and burns stored before transfers were tracked
+func transferEndpoints(tx *database.Transaction) (string, string) {
+    if tx.FromAddress != "" || tx.ToAddress != "" {
+        return tx.FromAddress, tx.ToAddress
+    }
+
+    switch tx.EventType {
+    case "Mint":
+        return "", tx.AccountAddress
+    case "Burn":
+        return tx.AccountAddress, ""
+    }
+    return "", ""
+}
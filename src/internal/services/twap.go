@@ -0,0 +1,148 @@
+package services
+
+import (
+    "context"
+    "fmt"
+    "math"
+    "math/big"
+    "time"
+
+    "github.com/ethereum/go-ethereum/common"
+
+    "src/internal/config"
+    "src/internal/database"
+    "src/internal/handlers"
+)
+
+// TWAPService computes time-weighted average prices from the indexed swaps
+type TWAPService struct {
+    db     database.Service
+    tokens *TokenRegistry
+    chain  ChainReader
+}
+
+func NewTWAPService(db database.Service, tokens *TokenRegistry, chain ChainReader) *TWAPService {
+    return &TWAPService{
+        db:     db,
+        tokens: tokens,
+        chain:  chain,
+    }
+}
+
+// GetTWAP averages the pool tick over the trailing window. The tick set by a
+// swap holds until the next swap; the tick in effect at the start of the
+// window comes from the last swap before it.
+func (s *TWAPService) GetTWAP(ctx context.Context, poolAddress, window string, verify bool) (*handlers.TWAP, error) {
+    if !common.IsHexAddress(poolAddress) {
+        return nil, fmt.Errorf("invalid pool address %q: %w", poolAddress, handlers.ErrInvalidInput)
+    }
+    poolAddress = common.HexToAddress(poolAddress).Hex()
+
+    duration, err := config.ParseWindow(window)
+    if err != nil {
+        return nil, fmt.Errorf("%v: %w", err, handlers.ErrInvalidInput)
+    }
+    seconds := int64(duration / time.Second)
+    if seconds < 1 || seconds > math.MaxUint32 {
+        return nil, fmt.Errorf("window must be between 1s and %ds: %w", uint32(math.MaxUint32), handlers.ErrInvalidInput)
+    }
+
+    token0Address, token1Address, err := poolTokens(ctx, s.db, poolAddress)
+    if err != nil {
+        return nil, err
+    }
+
+    to := time.Now().UTC().Truncate(time.Second)
+    from := to.Add(-time.Duration(seconds) * time.Second)
+
+    previous, err := s.db.GetSwapBefore(ctx, poolAddress, from)
+    if err != nil {
+        return nil, err
+    }
+    swaps, err := s.db.GetSwaps(ctx, poolAddress, from.Add(time.Second), to.Add(time.Second))
+    if err != nil {
+        return nil, err
+    }
+    if previous == nil && len(swaps) == 0 {
+        return nil, fmt.Errorf("no swaps indexed for pool %s: %w", poolAddress, handlers.ErrNotFound)
+    }
+
+    // Accumulate tick * seconds the same way the pool's oracle does
+    cumulative := new(big.Int)
+    cursor := from
+    tick := 0
+    if previous != nil {
+        tick = previous.Tick
+    } else {
+        cursor = swaps[0].Timestamp.UTC()
+        tick = swaps[0].Tick
+    }
+    covered := to.Sub(cursor)
+
+    for _, swap := range swaps {
+        elapsed := int64(swap.Timestamp.UTC().Sub(cursor) / time.Second)
+        cumulative.Add(cumulative, new(big.Int).Mul(big.NewInt(int64(tick)), big.NewInt(elapsed)))
+        cursor = swap.Timestamp.UTC()
+        tick = swap.Tick
+    }
+    elapsed := int64(to.Sub(cursor) / time.Second)
+    cumulative.Add(cumulative, new(big.Int).Mul(big.NewInt(int64(tick)), big.NewInt(elapsed)))
+
+    meanTick := tick
+    coveredSeconds := int64(covered / time.Second)
+    if coveredSeconds > 0 {
+        meanTick = floorDiv(cumulative, coveredSeconds)
+    }
+
+    token0 := s.tokens.Get(ctx, token0Address)
+    token1 := s.tokens.Get(ctx, token1Address)
+    price := priceAtTick(meanTick, token0.Decimals, token1.Decimals)
+
+    twap := &handlers.TWAP{
+        PoolAddress:    poolAddress,
+        Token0Address:  token0Address,
+        Token1Address:  token1Address,
+        Window:         window,
+        WindowSeconds:  seconds,
+        From:           from,
+        To:             to,
+        CoveredSeconds: coveredSeconds,
+        Swaps:          len(swaps),
+        MeanTick:       meanTick,
+        Price:          formatPrice(price),
+        InversePrice:   formatPrice(invertPrice(price)),
+    }
+
+    if verify {
+        twap.Oracle = s.oracleTWAP(ctx, poolAddress, uint32(seconds), meanTick, token0.Decimals, token1.Decimals)
+    }
+
+    return twap, nil
+}
+
+// oracleTWAP reads the mean tick over the window from the pool's observe().
+// The call fails when the oracle's history is shorter than the window.
+func (s *TWAPService) oracleTWAP(ctx context.Context, poolAddress string, seconds uint32, meanTick int, decimals0, decimals1 uint8) *handlers.OracleTWAP {
+    cumulatives, err := s.chain.Observe(ctx, poolAddress, []uint32{seconds, 0})
+    if err != nil {
+        return &handlers.OracleTWAP{Error: err.Error()}
+    }
+    if len(cumulatives) != 2 {
+        return &handlers.OracleTWAP{Error: fmt.Sprintf("expected 2 tick cumulatives, got %d", len(cumulatives))}
+    }
+
+    delta := new(big.Int).Sub(cumulatives[1], cumulatives[0])
+    oracleTick := floorDiv(delta, int64(seconds))
+
+    return &handlers.OracleTWAP{
+        MeanTick:      oracleTick,
+        Price:         formatPrice(priceAtTick(oracleTick, decimals0, decimals1)),
+        TickDeviation: meanTick - oracleTick,
+    }
+}
+
+// floorDiv divides by a positive y rounding towards negative infinity, like
+// OracleLibrary.consult. big.Int.Div is Euclidean, which floors for y > 0.
+func floorDiv(x *big.Int, y int64) int {
+    return int(new(big.Int).Div(x, big.NewInt(y)).Int64())
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+    "context"
+    "math/big"
+    "time"
+
+    "src/internal/config"
+    "src/internal/database"
+    "src/internal/handlers"
+)
+
+// feeDenominator is the scale of a pool's fee tier, 3000 = 0.3%
+const feeDenominator = 1_000_000
+
+// volumeWindow is a trailing period volume is reported over
+type volumeWindow struct {
+    label    string
+    duration time.Duration
+}
+
+// parseVolumeWindows skips invalid windows, the configuration has already been validated
+func parseVolumeWindows(windows []string) []volumeWindow {
+    parsed := make([]volumeWindow, 0, len(windows))
+    for _, window := range windows {
+        duration, err := config.ParseWindow(window)
+        if err != nil {
+            continue
+        }
+        parsed = append(parsed, volumeWindow{label: window, duration: duration})
+    }
+    return parsed
+}
+
+// feeTier returns the pool's fee from the factory's PoolCreated event, or
+// from the pool itself for pools that were registered directly
+func (s *PoolService) feeTier(ctx context.Context, poolAddress string) (uint32, error) {
+    pool, err := s.db.GetPool(ctx, poolAddress)
+    if err != nil {
+        return 0, err
+    }
+    if pool != nil {
+        return pool.Fee, nil
+    }
+    return s.chain.Fee(ctx, poolAddress)
+}
+
+// swapInflows returns the amounts a swap paid into the pool. The input side
+// of a swap is positive and the output side negative, so each swap counts
+// once towards the volume of the token that was sold.
+func swapInflows(tx *database.PoolTransaction) (*big.Int, *big.Int) {
+    inflow0, inflow1 := new(big.Int), new(big.Int)
+    if amount0, ok := new(big.Int).SetString(tx.Amount0, 10); ok && amount0.Sign() > 0 {
+        inflow0 = amount0
+    }
+    if amount1, ok := new(big.Int).SetString(tx.Amount1, 10); ok && amount1.Sign() > 0 {
+        inflow1 = amount1
+    }
+    return inflow0, inflow1
+}
+
+// withDay returns windows with a 24h window added, before the first longer
+// one, unless it already has one
+func withDay(windows []volumeWindow) []volumeWindow {
+    at := len(windows)
+    for i, window := range windows {
+        if window.duration == 24*time.Hour {
+            return windows
+        }
+        if window.duration > 24*time.Hour && at == len(windows) {
+            at = i
+        }
+    }
+
+    withDay := make([]volumeWindow, 0, len(windows)+1)
+    withDay = append(withDay, windows[:at]...)
+    withDay = append(withDay, volumeWindow{label: "24h", duration: 24 * time.Hour})
+    return append(withDay, windows[at:]...)
+}
+
+// setVolume reports swap volume and fee revenue over each configured window
+// and the last 24h, whose value in both tokens is the volume_24h summary
+func (s *PoolService) setVolume(ctx context.Context, status *handlers.PoolStatus, transactions []*database.PoolTransaction, fee uint32, valuer *poolValuer) {
+    token0 := s.tokens.Get(ctx, status.Token0Address)
+    token1 := s.tokens.Get(ctx, status.Token1Address)
+
+    now := time.Now()
+    for _, window := range withDay(s.windows) {
+        since := now.Add(-window.duration)
+
+        var swaps int64
+        volume0, volume1 := new(big.Int), new(big.Int)
+        for _, tx := range transactions {
+            if tx.EventType != "Swap" || !tx.Timestamp.After(since) {
+                continue
+            }
+            inflow0, inflow1 := swapInflows(tx)
+            volume0.Add(volume0, inflow0)
+            volume1.Add(volume1, inflow1)
+            swaps++
+        }
+
+        // Fees are charged on the input amount
+        fees0 := new(big.Int).Mul(volume0, big.NewInt(int64(fee)))
+        fees0.Quo(fees0, big.NewInt(feeDenominator))
+        fees1 := new(big.Int).Mul(volume1, big.NewInt(int64(fee)))
+        fees1.Quo(fees1, big.NewInt(feeDenominator))
+
+        volume := handlers.PoolVolume{
+            Window:    window.label,
+            Swaps:     swaps,
+            Token0:    formatUnits(volume0, token0.Decimals),
+            Token1:    formatUnits(volume1, token1.Decimals),
+            Token0Raw: volume0.String(),
+            Token1Raw: volume1.String(),
+            Fees0:     formatUnits(fees0, token0.Decimals),
+            Fees1:     formatUnits(fees1, token1.Decimals),
+            Fees0Raw:  fees0.String(),
+            Fees1Raw:  fees1.String(),
+        }
+        if valuer != nil {
+            volume.Value = formatPrice(valuer.value(
+                new(big.Rat).SetFrac(volume0, pow10(token0.Decimals)),
+                new(big.Rat).SetFrac(volume1, pow10(token1.Decimals)),
+            ))
+            volume.FeesValue = formatPrice(valuer.value(
+                new(big.Rat).SetFrac(fees0, pow10(token0.Decimals)),
+                new(big.Rat).SetFrac(fees1, pow10(token1.Decimals)),
+            ))
+            volume.Unit = valuer.unit
+        }
+
+        if window.duration == 24*time.Hour {
+            status.Volume24h = volume.Value
+            status.Volume24hUnit = volume.Unit
+        }
+        status.Volume = append(status.Volume, volume)
+    }
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+    "context"
+    "math/big"
+    "testing"
+    "time"
+
+    "src/internal/database"
+    "src/internal/handlers"
+)
+
+func TestWithDay(t *testing.T) {
+    tests := []struct {
+        configured []string
+        want       []string
+    }{
+        {nil, []string{"24h"}},
+        {[]string{"1h", "7d", "30d"}, []string{"1h", "24h", "7d", "30d"}},
+        {[]string{"1h", "24h", "7d"}, []string{"1h", "24h", "7d"}},
+        {[]string{"1d"}, []string{"1d"}},
+        {[]string{"1h", "6h"}, []string{"1h", "6h", "24h"}},
+    }
+
+    for _, tt := range tests {
+        var got []string
+        for _, window := range withDay(parseVolumeWindows(tt.configured)) {
+            got = append(got, window.label)
+        }
+        if len(got) != len(tt.want) {
+            t.Errorf("withDay(%v) = %v, want %v", tt.configured, got, tt.want)
+            continue
+        }
+        for i := range got {
+            if got[i] != tt.want[i] {
+                t.Errorf("withDay(%v) = %v, want %v", tt.configured, got, tt.want)
+                break
+            }
+        }
+    }
+}
+
+// The 24h summary values both sides of the swaps, not just the token0 sold
+func TestSetVolume(t *testing.T) {
+    const (
+        usdc = "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+        weth = "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"
+    )
+    tokens := NewTokenRegistry(nil, nil)
+    tokens.cache[usdc] = &database.TokenMetadata{Address: usdc, Decimals: 6}
+    tokens.cache[weth] = &database.TokenMetadata{Address: weth, Decimals: 18}
+    s := &PoolService{tokens: tokens, windows: parseVolumeWindows([]string{"1h", "7d"})}
+
+    now := time.Now()
+    swap := func(ago time.Duration, amount0, amount1 string) *database.PoolTransaction {
+        return &database.PoolTransaction{EventType: "Swap", Amount0: amount0, Amount1: amount1, Timestamp: now.Add(-ago)}
+    }
+    transactions := []*database.PoolTransaction{
+        swap(30*time.Minute, "2000000000", "-990000000000000000"), // 2000 USDC in
+        swap(2*time.Hour, "-1990000000", "1000000000000000000"),   // 1 WETH in
+        swap(72*time.Hour, "4000000000", "-1980000000000000000"),  // 4000 USDC in
+        {EventType: "Mint", Amount0: "5000000000", Amount1: "2000000000000000000", Timestamp: now},
+    }
+
+    status := &handlers.PoolStatus{Token0Address: usdc, Token1Address: weth}
+    valuer := &poolValuer{price: big.NewRat(1, 2000), rate: big.NewRat(1, 1), unit: weth}
+    s.setVolume(context.Background(), status, transactions, 3000, valuer)
+
+    if want := formatPrice(big.NewRat(2, 1)); status.Volume24h != want || status.Volume24hUnit != weth {
+        t.Errorf("volume_24h = %s %s, want %s %s", status.Volume24h, status.Volume24hUnit, want, weth)
+    }
+
+    want := []struct {
+        window string
+        swaps  int64
+        token0 string
+        token1 string
+    }{
+        {"1h", 1, "2000000000", "0"},
+        {"24h", 2, "2000000000", "1000000000000000000"},
+        {"7d", 3, "6000000000", "1000000000000000000"},
+    }
+    if len(status.Volume) != len(want) {
+        t.Fatalf("got %d volume windows, want %d", len(status.Volume), len(want))
+    }
+    for i, volume := range status.Volume {
+        if volume.Window != want[i].window || volume.Swaps != want[i].swaps ||
+            volume.Token0Raw != want[i].token0 || volume.Token1Raw != want[i].token1 {
+            t.Errorf("volume %d = %s: %d swaps, %s/%s, want %+v", i, volume.Window, volume.Swaps, volume.Token0Raw, volume.Token1Raw, want[i])
+        }
+    }
+    if day := status.Volume[1]; day.Fees0Raw != "6000000" || day.Fees1Raw != "3000000000000000" {
+        t.Errorf("24h fees = %s/%s, want 6000000/3000000000000000", day.Fees0Raw, day.Fees1Raw)
+    }
+}